@@ -0,0 +1,129 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/emer/etable/etensor"
+	"github.com/goki/ki/ints"
+	"github.com/goki/mat32"
+)
+
+// Corruption degrades the clean popcode patterns of an ExEnv, to test the
+// robustness of the network.  Noise, Dropout and Occlude are applied to the
+// States returned by ExEnv.State that are inputs on the current trial, i.e.,
+// not in ExEnv.Targs, and Jitter to the points as they are encoded.
+type Corruption struct {
+	Noise   float32  `desc:"standard deviation of Gaussian noise added to each unit of the States"`
+	Dropout float32  `min:"0" max:"1" desc:"probability of zeroing each unit of the States"`
+	Occlude int      `desc:"side, in units, of a square at a random position in each of the States that is zeroed -- 0 for none"`
+	Jitter  float32  `desc:"standard deviation, in grid units, of a random offset of the popcode center of each input point"`
+	States  []string `desc:"names of the states that Noise, Dropout and Occlude apply to"`
+}
+
+// Defaults sets the States to the 2D input maps -- EgoInput and AlloInput
+// are targets on some trials, when they are left clean
+func (cr *Corruption) Defaults() {
+	cr.States = []string{"Attn", "AlloInput", "EgoInput"}
+}
+
+// On returns true if any of Noise, Dropout or Occlude is on
+func (cr *Corruption) On() bool {
+	return cr.Noise > 0 || cr.Dropout > 0 || cr.Occlude > 0
+}
+
+// Corrupts returns true if given state is corrupted
+func (cr *Corruption) Corrupts(element string) bool {
+	if !cr.On() {
+		return false
+	}
+	for _, s := range cr.States {
+		if s == element {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply corrupts given pattern in place, with Noise, then Dropout, then
+// Occlude, keeping values within 0..1 -- Occlude only applies to 2D patterns
+func (cr *Corruption) Apply(tsr *etensor.Float32, rnd *rand.Rand) {
+	for i, v := range tsr.Values {
+		if cr.Noise > 0 {
+			v += cr.Noise * float32(rnd.NormFloat64())
+		}
+		if cr.Dropout > 0 && rnd.Float32() < cr.Dropout {
+			v = 0
+		}
+		tsr.Values[i] = mat32.Clamp(v, 0, 1)
+	}
+	if cr.Occlude <= 0 || tsr.NumDims() != 2 {
+		return
+	}
+	ny, nx := tsr.Dim(0), tsr.Dim(1)
+	sy, sx := ints.MinInt(cr.Occlude, ny), ints.MinInt(cr.Occlude, nx)
+	y0, x0 := rnd.Intn(ny-sy+1), rnd.Intn(nx-sx+1)
+	for y := y0; y < y0+sy; y++ {
+		for x := x0; x < x0+sx; x++ {
+			tsr.Set([]int{y, x}, 0)
+		}
+	}
+}
+
+// String returns the spec of the corruption, as parsed by ParseCorruption
+func (cr *Corruption) String() string {
+	var its []string
+	if cr.Noise > 0 {
+		its = append(its, fmt.Sprintf("noise:%g", cr.Noise))
+	}
+	if cr.Dropout > 0 {
+		its = append(its, fmt.Sprintf("dropout:%g", cr.Dropout))
+	}
+	if cr.Occlude > 0 {
+		its = append(its, fmt.Sprintf("occlude:%d", cr.Occlude))
+	}
+	if cr.Jitter > 0 {
+		its = append(its, fmt.Sprintf("jitter:%g", cr.Jitter))
+	}
+	return strings.Join(its, ";")
+}
+
+// ParseCorruption returns the Corruption for given spec, which is a
+// semicolon-separated list of kind:value items, e.g., noise:0.1;occlude:3
+// -- kinds are noise, dropout, occlude and jitter
+func ParseCorruption(spec string) (Corruption, error) {
+	cr := Corruption{}
+	cr.Defaults()
+	for _, it := range strings.Split(spec, ";") {
+		it = strings.TrimSpace(it)
+		if it == "" {
+			continue
+		}
+		kv := strings.SplitN(it, ":", 2)
+		if len(kv) != 2 {
+			return cr, fmt.Errorf("ParseCorruption: item: %v is not of the form kind:value", it)
+		}
+		var err error
+		switch kv[0] {
+		case "noise":
+			_, err = fmt.Sscanf(kv[1], "%g", &cr.Noise)
+		case "dropout":
+			_, err = fmt.Sscanf(kv[1], "%g", &cr.Dropout)
+		case "occlude":
+			_, err = fmt.Sscanf(kv[1], "%d", &cr.Occlude)
+		case "jitter":
+			_, err = fmt.Sscanf(kv[1], "%g", &cr.Jitter)
+		default:
+			return cr, fmt.Errorf("ParseCorruption: item: %v has unknown kind -- must be noise, dropout, occlude or jitter", it)
+		}
+		if err != nil {
+			return cr, fmt.Errorf("ParseCorruption: item: %v: %v", it, err)
+		}
+	}
+	return cr, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+func TestParseCorruption(t *testing.T) {
+	spec := "noise:0.1;dropout:0.2;occlude:3;jitter:0.5"
+	cr, err := ParseCorruption(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cr.String() != spec {
+		t.Errorf("spec %v parsed as %v", spec, cr.String())
+	}
+	if _, err := ParseCorruption("blur:1"); err == nil {
+		t.Errorf("expected error for unknown kind")
+	}
+}
+
+func TestCorruptStates(t *testing.T) {
+	ev := ExEnv{}
+	ev.Config(9, 10)
+	ev.NewPoint()
+	if ev.State("AlloInput") != ev.CleanState("AlloInput") {
+		t.Errorf("State corrupted with no corruption on")
+	}
+
+	ev.Corrupt.Dropout = 1
+	al := ev.State("AlloInput").(*etensor.Float32)
+	for i, v := range al.Values {
+		if v != 0 {
+			t.Fatalf("unit %d: %g not dropped out", i, v)
+		}
+	}
+	if _, mx, _, _ := ev.AlloInput.Range(); mx == 0 {
+		t.Errorf("clean AlloInput was corrupted")
+	}
+	if ev.State("Distance") != ev.CleanState("Distance") {
+		t.Errorf("Distance target corrupted")
+	}
+
+	ev.Corrupt.Dropout = 0
+	ev.Corrupt.Occlude = 3
+	al = ev.State("AlloInput").(*etensor.Float32)
+	nz := 0
+	for _, v := range al.Values {
+		if v == 0 {
+			nz++
+		}
+	}
+	if nz < 9 {
+		t.Errorf("only %d units occluded", nz)
+	}
+
+	ev.Corrupt.Occlude = 0
+	ev.Corrupt.Noise = 0.2
+	al = ev.State("AlloInput").(*etensor.Float32)
+	ndiff := 0
+	for i, v := range al.Values {
+		if v < 0 || v > 1 {
+			t.Errorf("unit %d: noisy value %g out of range", i, v)
+		}
+		if v != ev.AlloInput.Values[i] {
+			ndiff++
+		}
+	}
+	if ndiff == 0 {
+		t.Errorf("no noise added")
+	}
+
+	ev.Targs = []string{"EgoInput", "AlloInput"} // e.g., a RelationTask trial with AlloTarg
+	for _, st := range ev.Corrupt.States {
+		tsr := ev.State(st)
+		if ev.IsTarg(st) != (tsr == ev.CleanState(st)) {
+			t.Errorf("%v: target: %v, but corrupted: %v", st, ev.IsTarg(st), tsr != ev.CleanState(st))
+		}
+	}
+}
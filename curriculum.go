@@ -0,0 +1,120 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CurricStage is one stage of a Curriculum: the band of displacement
+// lengths and the range of bearings sampled during the stage
+type CurricStage struct {
+	MinDist  float32 `desc:"minimum length of the displacements"`
+	MaxDist  int     `desc:"maximum length of the displacements"`
+	MaxAngle int     `desc:"bearings are sampled from 0 up to this angle in degrees -- 360 for all"`
+	Epoch    int     `desc:"epoch at which this stage starts, when advancing by epoch"`
+}
+
+// Curriculum widens the task that TrainEnv samples from as training
+// progresses, through a sequence of Stages.  It advances either at the
+// Epoch of each stage, or if Auto, when the epoch error stats fall below
+// the criteria.
+type Curriculum struct {
+	Stages      []CurricStage `desc:"stages, in order from narrowest to widest task"`
+	Auto        bool          `desc:"if true, advance to the next stage when EpcPctErr is at most PctErrCrit and EpcDistError is at most DistErrCrit, instead of at the Epoch of each stage"`
+	PctErrCrit  float64       `desc:"when Auto, the EpcPctErr criterion for advancing"`
+	DistErrCrit float64       `desc:"when Auto, the EpcDistError criterion for advancing -- as a fraction of the DistNorm of the env, like EpcDistError"`
+	Stage       int           `inactive:"+" desc:"index of the current stage"`
+}
+
+// Defaults sets the Auto criteria -- EpcDistError is relative to the
+// DistNorm of the env, which does not change from stage to stage
+func (cu *Curriculum) Defaults() {
+	cu.PctErrCrit = 0.2
+	cu.DistErrCrit = 0.05
+}
+
+// IsEmpty returns true if there are no stages
+func (cu *Curriculum) IsEmpty() bool {
+	return len(cu.Stages) == 0
+}
+
+// Init goes back to the first stage, and sets it on given env
+func (cu *Curriculum) Init(ev *ExEnv) error {
+	cu.Stage = 0
+	return cu.Apply(ev)
+}
+
+// Apply sets the current stage on given env, and validates it -- if it is
+// not valid, e.g., if a PathTask could get stuck at the edge of the grid,
+// the env is left as it was
+func (cu *Curriculum) Apply(ev *ExEnv) error {
+	if cu.IsEmpty() {
+		return nil
+	}
+	st := &cu.Stages[cu.Stage]
+	minDist, maxDist, maxAngle := ev.MinDist, ev.MaxDist, ev.MaxAngle
+	ev.MinDist = st.MinDist
+	ev.MaxDist = st.MaxDist
+	ev.MaxAngle = st.MaxAngle
+	if err := ev.Validate(); err != nil {
+		ev.MinDist, ev.MaxDist, ev.MaxAngle = minDist, maxDist, maxAngle
+		return fmt.Errorf("Curriculum: stage %d: %v", cu.Stage, err)
+	}
+	return nil
+}
+
+// Update advances to the next stage if the given epoch, which is the one
+// about to start, has reached its Epoch, or if Auto and the stats of the
+// epoch just finished meet the criteria.  Returns true if the stage changed,
+// in which case it is applied to given env.
+func (cu *Curriculum) Update(ev *ExEnv, epc int, pctErr, distErr float64) (bool, error) {
+	if cu.Stage >= len(cu.Stages)-1 {
+		return false, nil
+	}
+	if cu.Auto {
+		if pctErr > cu.PctErrCrit || distErr > cu.DistErrCrit {
+			return false, nil
+		}
+	} else if epc < cu.Stages[cu.Stage+1].Epoch {
+		return false, nil
+	}
+	cu.Stage++
+	return true, cu.Apply(ev)
+}
+
+// String returns the spec of the stages, as parsed by ParseCurriculum
+func (cu *Curriculum) String() string {
+	its := make([]string, len(cu.Stages))
+	for i, st := range cu.Stages {
+		its[i] = fmt.Sprintf("%g,%d,%d,%d", st.MinDist, st.MaxDist, st.MaxAngle, st.Epoch)
+	}
+	return strings.Join(its, ";")
+}
+
+// ParseCurriculum returns the Curriculum for given spec, which is a
+// semicolon-separated list of stages, each MinDist,MaxDist,MaxAngle,Epoch,
+// e.g., 2,4,90,0;2,8,180,10;4,12,360,20 -- the Epoch can be left out for Auto
+func ParseCurriculum(spec string) (Curriculum, error) {
+	cu := Curriculum{}
+	cu.Defaults()
+	for _, it := range strings.Split(spec, ";") {
+		it = strings.TrimSpace(it)
+		if it == "" {
+			continue
+		}
+		st := CurricStage{}
+		n, err := fmt.Sscanf(it, "%g,%d,%d,%d", &st.MinDist, &st.MaxDist, &st.MaxAngle, &st.Epoch)
+		if n < 3 {
+			return cu, fmt.Errorf("ParseCurriculum: stage: %v is not of the form MinDist,MaxDist,MaxAngle,Epoch: %v", it, err)
+		}
+		if st.MaxAngle <= 0 || st.MaxAngle > 360 {
+			return cu, fmt.Errorf("ParseCurriculum: stage: %v has MaxAngle out of range 1..360", it)
+		}
+		cu.Stages = append(cu.Stages, st)
+	}
+	return cu, nil
+}
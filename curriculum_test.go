@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCurriculum(t *testing.T) {
+	spec := "2,4,90,0;2,8,180,10;4,12,360,20"
+	cu, err := ParseCurriculum(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cu.String() != spec {
+		t.Errorf("spec %v parsed as %v", spec, cu.String())
+	}
+	if _, err := ParseCurriculum("2,4,400"); err == nil {
+		t.Errorf("expected error for MaxAngle out of range")
+	}
+
+	ev := ExEnv{}
+	ev.Config(9, 10)
+	ev.SetSampler("lattice")
+	if err := cu.Init(&ev); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 200; i++ {
+		ev.NewPoint()
+		if ev.DistVal < 2 || ev.DistVal > 4 || ev.AngVal >= 90 {
+			t.Fatalf("stage 0: distance %g, angle %g out of range", ev.DistVal, ev.AngVal)
+		}
+	}
+	for epc := 1; epc <= 25; epc++ {
+		chg, err := cu.Update(&ev, epc, 1, math.Inf(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chg != (epc == 10 || epc == 20) {
+			t.Errorf("epoch %d: stage changed: %v to %d", epc, chg, cu.Stage)
+		}
+	}
+	if ev.MaxDist != 12 || ev.MaxAngle != 360 {
+		t.Errorf("last stage not applied: MaxDist %d, MaxAngle %d", ev.MaxDist, ev.MaxAngle)
+	}
+
+	cu.Auto = true
+	cu.Init(&ev)
+	if chg, _ := cu.Update(&ev, 50, 0.5, 0.5); chg {
+		t.Errorf("advanced with PctErr above criterion")
+	}
+	if chg, _ := cu.Update(&ev, 50, 0.1, 0.5); chg {
+		t.Errorf("advanced with a DistError of half of DistNorm")
+	}
+	if chg, _ := cu.Update(&ev, 1, 0.1, 0.01); !chg || cu.Stage != 1 {
+		t.Errorf("did not advance with stats below criteria")
+	}
+
+	norm := ev.DistNorm()
+	wcu, _ := ParseCurriculum("2,4,90,0;2,20,360,10") // 20 is beyond DistPop.Max of 13.2
+	if err := wcu.Init(&ev); err != nil || ev.DistNorm() != norm {
+		t.Errorf("stage 0: %v, DistNorm %g changed from %g", err, ev.DistNorm(), norm)
+	}
+	if _, err := wcu.Update(&ev, 10, 1, 1); err == nil {
+		t.Errorf("expected error for a stage with MaxDist beyond DistPop.Max %g", ev.DistPop.Max)
+	}
+
+	pev := ExEnv{}
+	pev.Task = PathTask
+	pev.Config(9, 10)
+	if err := pev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	pcu, _ := ParseCurriculum("4,8,90,0") // only +X steps with the axis sampler
+	if err := pcu.Init(&pev); err == nil {
+		t.Errorf("expected error for a PathTask stage that gets stuck at the edge")
+	}
+	if pev.MaxAngle != 360 || pev.Validate() != nil {
+		t.Errorf("invalid stage left applied: MaxAngle %d", pev.MaxAngle)
+	}
+}
@@ -7,14 +7,17 @@ package main
 import (
 	"fmt"
 	"image"
+	"log"
 	"math"
 	"math/rand"
-	"time"
+	"strings"
 
 	"github.com/emer/emergent/env"
 	"github.com/emer/emergent/popcode"
 	"github.com/emer/etable/etensor"
+	"github.com/goki/ki/kit"
 	"github.com/goki/mat32"
+	"myEnv/geom"
 )
 
 // ExEnv is an example environment, that sets a single input point in a 2D
@@ -27,31 +30,114 @@ type ExEnv struct {
 	Size         int    `desc:"size of each dimension in 2D input"`
 	MinDist      float32
 	MaxDist      int
-	MaxAngle     int
+	MaxAngle     int `desc:"displacements are sampled with bearings from 0 up to this angle in degrees -- 360 for all"`
 	NAngleUnits  int
 	NDistUnits   int
-	DistPop      popcode.OneD `desc:"population encoding of distance value"`
+	Task         TaskModes                   `desc:"which task is posed on each trial"`
+	NSteps       int                         `desc:"in PathTask, number of displacement steps in each sequence"`
+	NDistract    int                         `desc:"number of distractor points added to AlloInput, each with a different feature than Point2 -- the feature of Point2 is cued on FeatCue"`
+	NFeats       int                         `desc:"number of different object features, for Point2 and the distractors"`
+	Sampler      string                      `desc:"name of the displacement sampler -- axis, compass, lattice or angle"`
+	Disp         DispSampler                 `view:"-" desc:"displacement sampler used by NewPoint, set from Sampler"`
+	Enum         bool                        `desc:"if true, Step goes through every valid (start point, displacement) combination that the sampler can produce, in a fixed order, instead of sampling -- Trial.Max is set to the number of combinations.  Only the points, transform and scale are enumerated: the heading, distractors and AnalogyC point are still sampled at random on each trial"`
+	Trials       []PointTrial                `view:"-" desc:"all the trials in order, when Enum is on"`
+	Continuous   bool                        `desc:"if true, NewPoint samples real-valued start points and displacements anywhere within the grid, instead of only on the integer lattice -- Enum always goes through the lattice"`
+	Wrap         bool                        `desc:"if true, the grid is a torus: positions wrap around at the edges, displacements are the shortest toroidal vectors, up to Size/2 along each axis, and start points are sampled over the whole grid for every displacement"`
+	XformSet     []Transform                 `desc:"transforms that XformCue has a unit for, with the identity first -- see ParseTransforms -- all the envs of a network need the same set, which is just the identity by default"`
+	Xforms       []int                       `desc:"indexes in XformSet of the transforms applied to the displacement of each trial, chosen at random, or each in turn when Enum -- empty for none"`
+	Xform        int                         `inactive:"+" desc:"index in XformSet of the transform applied to the current trial -- 0 is none"`
+	Scales       []float32                   `desc:"factors that the source displacement Point2 - Point is scaled by, one chosen at random on each trial, or each in turn when Enum, and cued on ScaleCue: the Distance target is the length of the scaled displacement, and in AnalogyTask, D = C + factor * (B - A) -- empty for no scaling"`
+	ScaleVal     float32                     `inactive:"+" desc:"scale factor of the current trial -- 1 if no Scales"`
+	NScaleUnits  int                         `desc:"number of units in the ScaleCue popcode"`
+	HeadingOn    bool                        `desc:"if true, the agent has a random heading on each trial (or path), presented on the Heading state, and EgoInput shows the displacement rotated into the agent's frame, so that the ego and allo frames differ by a rotation"`
+	HeadingStep  float32                     `desc:"headings are sampled at multiples of this many degrees, e.g., 90 keeps the ego vectors on the lattice -- 0 for any heading"`
+	EgoAngle     bool                        `desc:"if true, the Angle target is the egocentric bearing, relative to the heading, instead of the allocentric bearing"`
+	NHeadUnits   int                         `desc:"number of units in the Heading ring popcode"`
+	HoldOut      HoldOut                     `desc:"trials held out of training, to test generalization"`
+	ExclHeld     bool                        `desc:"if true, held-out trials are never generated -- on for training, off for testing so they are reported separately"`
+	IsHeld       bool                        `inactive:"+" desc:"true if the current trial is in the HoldOut set"`
+	Corrupt      Corruption                  `desc:"corruption of the clean input patterns, e.g., to test robustness"`
+	CorTsrs      map[string]*etensor.Float32 `view:"-" desc:"corrupted copies of the states returned by State"`
+	XfInputs     map[string]*etensor.Float32 `view:"-" desc:"when a transform applies, the states that depend on the displacement, encoded from the untransformed InDisp -- State returns them for the inputs"`
+	Targs        []string                    `view:"-" desc:"names of the states that are targets on the current trial, which State does not corrupt -- set by the Sim from the layer types as it applies the states"`
+	Seed         int64                       `inactive:"+" desc:"seed of Rand -- set per run by the Sim so that every run can be regenerated exactly"`
+	Rand         *rand.Rand                  `view:"-" desc:"random source for all sampling done by this environment"`
+	DistPop      popcode.OneD                `desc:"population encoding of distance value"`
 	AnglePop     popcode.Ring
+	HeadingPop   popcode.Ring `desc:"ring population encoding of the heading"`
+	ScalePop     popcode.OneD `desc:"population encoding of the scale factor"`
 	AttnPop      popcode.TwoD `desc:"2D population encoding of attn"`
 	AlloInputPop popcode.TwoD
 	EgoInputPop  popcode.TwoD
-	Point        image.Point `desc:"X,Y coordinates of point"`
-	Point2       image.Point
-	Point3       image.Point
+	Point        mat32.Vec2 `desc:"X,Y coordinates of point -- real-valued when Continuous"`
+	Point2       mat32.Vec2
+	Point3       mat32.Vec2
+	PointC       mat32.Vec2      `desc:"in AnalogyTask, start point C of the target pair"`
+	PointD       mat32.Vec2      `desc:"in AnalogyTask, completed point D = C + ScaleVal * (Point2 - Point)"`
+	StepDisp     mat32.Vec2      `desc:"in PathTask, displacement of the current step -- Point is the origin and Point2 the cumulative position"`
+	InDisp       mat32.Vec2      `inactive:"+" desc:"untransformed displacement of the current trial, that the inputs encode when a transform applies -- the targets encode the transformed Point2 - Point"`
+	Distractors  []mat32.Vec2    `inactive:"+" desc:"distractor points on AlloInput"`
+	Feats        []int           `inactive:"+" desc:"features of Point2 and then each of the Distractors"`
 	Attn         etensor.Float32 `desc: "attentional layer"`
 	EgoInput     etensor.Float32 `desc:"Egocentric input state, 2D Size x Size"`
 	AlloInput    etensor.Float32 `desc:"Allocentric input layer"`
+	AlloFeat     etensor.Float32 `desc:"with distractors, the locations of Point2 and the distractors in a separate AlloInput map for each feature"`
+	FeatCue      etensor.Float32 `desc:"with distractors, localist cue of the feature of Point2"`
+	XformCue     etensor.Float32 `desc:"localist cue of which of the XformSet transforms relates the input displacement to the target one on the current trial"`
+	AnalogyC     etensor.Float32 `desc:"in AnalogyTask, start point C of the target pair"`
+	AnalogyD     etensor.Float32 `desc:"in AnalogyTask, completed point D -- the target"`
+	Heading      etensor.Float32 `desc:"if HeadingOn, ring popcode of the heading"`
+	ScaleCue     etensor.Float32 `desc:"with Scales, popcode of the scale factor of the current trial"`
 	// X        etensor.Float32 `desc:"X as a one-hot state 1D Size"`
 	// Y        etensor.Float32 `desc:"Y  as a one-hot state 1D Size"`
 	Distance etensor.Float32
 	Angle    etensor.Float32
 	DistVal  float32
-	AngVal   float32
+	AngVal   float32 `desc:"bearing encoded on Angle -- egocentric if EgoAngle"`
+	HeadVal  float32 `inactive:"+" desc:"heading of the agent in degrees, counter-clockwise from the X axis -- 0 unless HeadingOn"`
 	Run      env.Ctr `view:"inline" desc:"current run of model as provided during Init"`
 	Epoch    env.Ctr `view:"inline" desc:"number of times through Seq.Max number of sequences"`
-	Trial    env.Ctr `view:"inline" desc:"trial increments over input states -- could add Event as a lower level"`
+	Trial    env.Ctr `view:"inline" desc:"trial increments over input states"`
+	Tick     env.Ctr `view:"inline" desc:"in PathTask, step within the sequence of each trial"`
 }
 
+// PointTrial is one (start point, displacement) combination
+type PointTrial struct {
+	Start  image.Point `desc:"start point, i.e., Point"`
+	Disp   image.Point `desc:"displacement from Start to Point2"`
+	InDisp image.Point `desc:"untransformed displacement, that the inputs encode -- Disp if no transform"`
+	Xform  int         `desc:"index in XformSet of the transform that was applied to InDisp to get Disp"`
+	Scale  float32     `desc:"factor that Disp is scaled by for the targets -- 1 if no Scales"`
+}
+
+// TaskModes are the different tasks that ExEnv can pose on each trial
+type TaskModes int32
+
+//go:generate stringer -type=TaskModes
+
+var KiT_TaskModes = kit.Enums.AddEnum(TaskModesN, kit.NotBitFlag, nil)
+
+func (ev TaskModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *TaskModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// The task modes
+const (
+	// RelationTask maps the AlloInput point pair to Distance and Angle, or back
+	RelationTask TaskModes = iota
+
+	// AnalogyTask poses A:B :: C:? -- the source pair A, B is on AlloInput,
+	// the start point C is on AnalogyC, and the completed point
+	// D = C + (B - A) is the target on AnalogyD
+	AnalogyTask
+
+	// PathTask presents a sequence of NSteps displacements one step at a
+	// time on EgoInput, and targets the cumulative position on AlloInput,
+	// and its Distance and Angle from the origin
+	PathTask
+
+	TaskModesN
+)
+
 func (ev *ExEnv) Name() string { return ev.Nm }
 func (ev *ExEnv) Desc() string { return ev.Dsc }
 
@@ -59,16 +145,40 @@ func (ev *ExEnv) Desc() string { return ev.Dsc }
 func (ev *ExEnv) Config(sz int, ntrls int) {
 	ev.Size = sz
 	ev.MaxDist = int(float64(sz) * math.Sqrt(2))
-	ev.MinDist = 5
+	ev.MinDist = float32((sz - 1) / 2)
 	ev.MaxAngle = 360
 	ev.NAngleUnits = 24
 	ev.NDistUnits = 10
+	if ev.NFeats == 0 {
+		ev.NFeats = 4
+	}
+	if ev.NSteps == 0 {
+		ev.NSteps = 3
+	}
+	if len(ev.XformSet) == 0 {
+		ev.XformSet = []Transform{Transforms[0]}
+	}
+	if ev.Corrupt.States == nil {
+		ev.Corrupt.Defaults()
+	}
+	if ev.Disp == nil {
+		ev.SetSampler("axis")
+	}
 	ev.DistPop.Defaults()
-	ev.DistPop.Min = float32(ev.MaxDist) * -0.1
-	ev.DistPop.Max = float32(ev.MaxDist) * 1.1
+	ev.DistPop.Min = float32(ev.MaxDist) * ev.MaxScale() * -0.1
+	ev.DistPop.Max = float32(ev.MaxDist) * ev.MaxScale() * 1.1
+	ev.ScaleVal = 1
+	ev.NScaleUnits = 12
+	ev.ScalePop.Defaults()
+	ev.ScalePop.Min = ev.MaxScale() * -0.1
+	ev.ScalePop.Max = ev.MaxScale() * 1.1
 	ev.AnglePop.Defaults()
 	ev.AnglePop.Min = 0
 	ev.AnglePop.Max = 360
+	ev.NHeadUnits = 16
+	ev.HeadingPop.Defaults()
+	ev.HeadingPop.Min = 0
+	ev.HeadingPop.Max = 360
 	ev.AlloInputPop.Defaults()
 	ev.EgoInputPop.Defaults()
 	ev.AttnPop.Defaults()
@@ -81,44 +191,175 @@ func (ev *ExEnv) Config(sz int, ntrls int) {
 	ev.EgoInputPop.Max = mat32.NewVec2(float32(sz*2), float32(sz*2))
 	ev.EgoInputPop.Sigma.Set(0.1, 0.1)
 
-	currentTime := time.Now()
-	rand.Seed(int64(currentTime.Unix()))
+	if ev.Rand == nil {
+		ev.SetSeed(ev.Seed)
+	}
 
 	ev.Trial.Max = ntrls
+	ev.ConfigStates()
+}
+
+// ConfigStates derives the shapes of the states, and the number of Ticks,
+// from Size and the other config fields -- called by Config, and again if
+// any of them are changed afterwards, e.g., by params
+func (ev *ExEnv) ConfigStates() {
+	sz := ev.Size
+	ev.Tick.Max = ev.NSteps
 	ev.EgoInput.SetShape([]int{sz*2 - 1, sz*2 - 1}, nil, []string{"Y", "X"})
 	ev.Attn.SetShape([]int{sz + 1, sz + 1}, nil, []string{"Y", "X"})
 	ev.AlloInput.SetShape([]int{sz*2 - 1, sz*2 - 1}, nil, []string{"Y", "X"})
+	ev.AlloFeat.SetShape([]int{1, ev.NFeats, sz*2 - 1, sz*2 - 1}, nil, []string{"1", "Feat", "Y", "X"})
+	ev.FeatCue.SetShape([]int{1, ev.NFeats}, nil, []string{"1", "Feat"})
+	ev.XformCue.SetShape([]int{1, len(ev.XformSet)}, nil, []string{"1", "Xform"})
+	ev.AnalogyC.SetShape([]int{sz*2 - 1, sz*2 - 1}, nil, []string{"Y", "X"})
+	ev.AnalogyD.SetShape([]int{sz*2 - 1, sz*2 - 1}, nil, []string{"Y", "X"})
 	// ev.X.SetShape([]int{sz}, nil, []string{"X"})
 	// ev.Y.SetShape([]int{sz}, nil, []string{"Y"})
 	ev.Distance.SetShape([]int{ev.NDistUnits}, nil, []string{"Distance"})
 	ev.Angle.SetShape([]int{ev.NAngleUnits}, nil, []string{"Angle"})
+	ev.Heading.SetShape([]int{ev.NHeadUnits}, nil, []string{"Heading"})
+	ev.ScaleCue.SetShape([]int{1, ev.NScaleUnits}, nil, []string{"1", "Scale"})
 }
 
 func (ev *ExEnv) Validate() error {
 	if ev.Size == 0 {
 		return fmt.Errorf("ExEnv: %v has size == 0 -- need to Config", ev.Nm)
 	}
+	if ev.Disp == nil {
+		return fmt.Errorf("ExEnv: %v has no displacement sampler -- call SetSampler", ev.Nm)
+	}
+	lo, hi := ev.DispRange()
+	if lo < 1 || lo > hi {
+		return fmt.Errorf("ExEnv: %v has empty displacement range %d..%d -- check MinDist: %g, MaxDist: %d, Size: %d", ev.Nm, lo, hi, ev.MinDist, ev.MaxDist, ev.Size)
+	}
+	if ev.MaxAngle <= 0 || ev.MaxAngle > 360 {
+		return fmt.Errorf("ExEnv: %v has MaxAngle: %d -- must be in 1..360", ev.Nm, ev.MaxAngle)
+	}
+	if float32(ev.MaxDist)*ev.MaxScale() > ev.DistPop.Max || ev.MinDist < ev.DistPop.Min {
+		return fmt.Errorf("ExEnv: %v has distances %g..%g, scaled by up to %g, outside the DistPop range %g..%g -- check MinDist, MaxDist and Scales", ev.Nm, ev.MinDist, float32(ev.MaxDist)*ev.MaxScale(), ev.MaxScale(), ev.DistPop.Min, ev.DistPop.Max)
+	}
+	if len(ev.Disp.Disps(ev)) == 0 {
+		return fmt.Errorf("ExEnv: %v has no displacements for the %v sampler -- check MinDist: %g, MaxDist: %d, MaxAngle: %d", ev.Nm, ev.Disp.Name(), ev.MinDist, ev.MaxDist, ev.MaxAngle)
+	}
+	mx := ev.Size - 1 // largest coordinate of Point, Point2
+	if mx >= ev.Attn.Dim(0) || mx >= ev.Attn.Dim(1) {
+		return fmt.Errorf("ExEnv: %v points up to %d do not fit in Attn shape %v", ev.Nm, mx, ev.Attn.Shp)
+	}
+	if mx >= ev.AlloInput.Dim(0) || mx >= ev.AlloInput.Dim(1) {
+		return fmt.Errorf("ExEnv: %v points up to %d do not fit in AlloInput shape %v", ev.Nm, mx, ev.AlloInput.Shp)
+	}
+	if ev.NDistract < 0 || ev.NDistract >= ev.NFeats {
+		return fmt.Errorf("ExEnv: %v has NDistract: %d -- must be less than NFeats: %d so that each object has its own feature", ev.Nm, ev.NDistract, ev.NFeats)
+	}
+	if ev.NDistract+2 > ev.Size*ev.Size {
+		return fmt.Errorf("ExEnv: %v has NDistract: %d -- too many to fit on the %d x %d grid", ev.Nm, ev.NDistract, ev.Size, ev.Size)
+	}
+	for _, xf := range ev.Xforms {
+		if xf < 0 || xf >= len(ev.XformSet) {
+			return fmt.Errorf("ExEnv: %v has transform index %d out of range of %d XformSet transforms", ev.Nm, xf, len(ev.XformSet))
+		}
+	}
+	if ev.HeadingStep < 0 || ev.HeadingStep >= 360 {
+		return fmt.Errorf("ExEnv: %v has HeadingStep: %g -- must be in 0..360", ev.Nm, ev.HeadingStep)
+	}
+	for _, s := range ev.Scales {
+		if s <= 0 {
+			return fmt.Errorf("ExEnv: %v has scale factor: %g -- must be positive", ev.Nm, s)
+		}
+		if !ev.ScaleFits(s) {
+			return fmt.Errorf("ExEnv: %v has scale factor: %g -- no displacement of at least MinDist: %g scaled by it fits in MaxDispAxis: %d, on the lattice unless Continuous", ev.Nm, s, ev.MinDist, ev.MaxDispAxis())
+		}
+	}
+	if ev.Task == PathTask && len(ev.Scales) > 0 {
+		return fmt.Errorf("ExEnv: %v cannot scale the displacements of PathTask sequences", ev.Nm)
+	}
+	if ev.Task == PathTask && ev.NSteps < 1 {
+		return fmt.Errorf("ExEnv: %v has NSteps: %d -- need at least 1 step in PathTask", ev.Nm, ev.NSteps)
+	}
+	if ev.Task == PathTask && !ev.PathOk() {
+		return fmt.Errorf("ExEnv: %v has PathTask positions where no displacement of the %v sampler stays on the grid -- check MaxAngle: %d, MinDist: %g", ev.Nm, ev.Disp.Name(), ev.MaxAngle, ev.MinDist)
+	}
+	if ev.Enum && ev.Task != PathTask && len(ev.AllTrials()) == 0 {
+		return fmt.Errorf("ExEnv: %v has no trials to enumerate -- check HoldOut: %v, MaxAngle: %d, Scales: %v", ev.Nm, ev.HoldOut.String(), ev.MaxAngle, ev.Scales)
+	}
+	if ev.ExclHeld && !ev.HoldOut.IsEmpty() && len(ev.AllTrials()) == 0 {
+		return fmt.Errorf("ExEnv: %v holds out every trial: %v", ev.Nm, ev.HoldOut.String())
+	}
+	c := ev.Center()
+	if c-hi < 0 || c+hi >= ev.EgoInput.Dim(0) || c+hi >= ev.EgoInput.Dim(1) {
+		return fmt.Errorf("ExEnv: %v displacements up to %d around center %d do not fit in EgoInput shape %v", ev.Nm, hi, c, ev.EgoInput.Shp)
+	}
 	return nil
 }
 
 func (ev *ExEnv) Counters() []env.TimeScales {
-	return []env.TimeScales{env.Run, env.Epoch, env.Trial}
+	return []env.TimeScales{env.Run, env.Epoch, env.Trial, env.Tick}
 }
 
+// States returns the shapes of the states, which are those of the tensors,
+// and match the Input and Target layers of the network -- see envcheck.Layers
 func (ev *ExEnv) States() env.Elements {
 	els := env.Elements{
-		{"EgoInput", []int{ev.Size, ev.Size}, []string{"Y", "X"}},
-		{"Attn", []int{ev.Size, ev.Size}, []string{"Y", "X"}},
-		{"AlloInput", []int{ev.Size, ev.Size}, []string{"Y", "X"}},
+		{"EgoInput", ev.EgoInput.Shapes(), ev.EgoInput.DimNames()},
+		{"Attn", ev.Attn.Shapes(), ev.Attn.DimNames()},
+		{"AlloInput", ev.AlloInput.Shapes(), ev.AlloInput.DimNames()},
+		{"AlloFeat", ev.AlloFeat.Shapes(), ev.AlloFeat.DimNames()},
+		{"FeatCue", ev.FeatCue.Shapes(), ev.FeatCue.DimNames()},
+		{"XformCue", ev.XformCue.Shapes(), ev.XformCue.DimNames()},
+		{"Heading", ev.Heading.Shapes(), ev.Heading.DimNames()},
+		{"ScaleCue", ev.ScaleCue.Shapes(), ev.ScaleCue.DimNames()},
+		{"AnalogyC", ev.AnalogyC.Shapes(), ev.AnalogyC.DimNames()},
+		{"AnalogyD", ev.AnalogyD.Shapes(), ev.AnalogyD.DimNames()},
 		// {"X", []int{ev.Size}, []string{"X"}},
 		// {"Y", []int{ev.Size}, []string{"Y"}},
-		{"Distance", []int{ev.Size}, []string{"Distance"}},
-		{"Angle", []int{ev.Size}, []string{"Angle"}},
+		{"Distance", ev.Distance.Shapes(), ev.Distance.DimNames()},
+		{"Angle", ev.Angle.Shapes(), ev.Angle.DimNames()},
 	}
 	return els
 }
 
+// State returns the given state.  For the inputs, i.e., the states that are
+// not listed in Targs, it returns the one encoded from the untransformed
+// InDisp if a transform applies, corrupted by a fresh draw of Corrupt if it
+// applies -- targets are always the clean, transformed states.
 func (ev *ExEnv) State(element string) etensor.Tensor {
+	tsr := ev.CleanState(element)
+	if tsr == nil || ev.IsTarg(element) {
+		return tsr
+	}
+	if xt, ok := ev.XfInputs[element]; ok && ev.Xform > 0 {
+		tsr = xt
+	}
+	if !ev.Corrupt.Corrupts(element) {
+		return tsr
+	}
+	if ev.CorTsrs == nil {
+		ev.CorTsrs = map[string]*etensor.Float32{}
+	}
+	ct, ok := ev.CorTsrs[element]
+	if !ok {
+		ct = &etensor.Float32{}
+		ev.CorTsrs[element] = ct
+	}
+	ct.CopyShapeFrom(tsr)
+	ct.CopyFrom(tsr)
+	ev.Corrupt.Apply(ct, ev.Rand)
+	return ct
+}
+
+// IsTarg returns true if given state is one of the Targs
+func (ev *ExEnv) IsTarg(element string) bool {
+	for _, s := range ev.Targs {
+		if s == element {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanState returns the given state as a target, without any corruption,
+// and transformed if a transform applies
+func (ev *ExEnv) CleanState(element string) etensor.Tensor {
 	switch element {
 	case "EgoInput":
 		return &ev.EgoInput
@@ -126,6 +367,38 @@ func (ev *ExEnv) State(element string) etensor.Tensor {
 		return &ev.Attn
 	case "AlloInput":
 		return &ev.AlloInput
+	case "AlloFeat":
+		if ev.NDistract == 0 {
+			return nil
+		}
+		return &ev.AlloFeat
+	case "FeatCue":
+		if ev.NDistract == 0 {
+			return nil
+		}
+		return &ev.FeatCue
+	case "XformCue":
+		return &ev.XformCue
+	case "Heading":
+		if !ev.HeadingOn {
+			return nil
+		}
+		return &ev.Heading
+	case "ScaleCue":
+		if len(ev.Scales) == 0 {
+			return nil
+		}
+		return &ev.ScaleCue
+	case "AnalogyC":
+		if ev.Task != AnalogyTask {
+			return nil
+		}
+		return &ev.AnalogyC
+	case "AnalogyD":
+		if ev.Task != AnalogyTask {
+			return nil
+		}
+		return &ev.AnalogyD
 	case "Distance":
 		return &ev.Distance
 	case "Angle":
@@ -140,7 +413,16 @@ func (ev *ExEnv) Actions() env.Elements {
 
 // String returns the current state as a string
 func (ev *ExEnv) String() string {
-	return fmt.Sprintf("Pt_%d_%d", ev.Point.X, ev.Point.Y)
+	switch ev.Task {
+	case AnalogyTask:
+		return fmt.Sprintf("Pt_%.4g_%.4g_%.4g_%.4g_C_%.4g_%.4g", ev.Point.X, ev.Point.Y, ev.Point2.X, ev.Point2.Y, ev.PointC.X, ev.PointC.Y)
+	case PathTask:
+		return fmt.Sprintf("Pt_%.4g_%.4g_Pos_%.4g_%.4g_Step_%d", ev.Point.X, ev.Point.Y, ev.Point2.X, ev.Point2.Y, ev.Tick.Cur)
+	}
+	if ev.Xform > 0 {
+		return fmt.Sprintf("Pt_%.4g_%.4g_%s", ev.Point.X, ev.Point.Y, ev.XformSet[ev.Xform].Name)
+	}
+	return fmt.Sprintf("Pt_%.4g_%.4g", ev.Point.X, ev.Point.Y)
 }
 
 // Init is called to restart environment
@@ -148,139 +430,755 @@ func (ev *ExEnv) Init(run int) {
 	ev.Run.Scale = env.Run
 	ev.Epoch.Scale = env.Epoch
 	ev.Trial.Scale = env.Trial
+	ev.Tick.Scale = env.Tick
 	ev.Run.Init()
 	ev.Epoch.Init()
 	ev.Trial.Init()
+	ev.Tick.Init()
 	ev.Run.Cur = run
 	ev.Trial.Cur = -1 // init state -- key so that first Step() = 0
+	ev.Tick.Cur = -1
+	if ev.Enum && ev.Task != PathTask {
+		if err := ev.EnumTrials(); err != nil {
+			log.Println(err)
+		}
+		ev.Trial.Max = len(ev.Trials)
+	}
 }
 
-// NewPoint generates a new point and sets state accordingly
-func (ev *ExEnv) NewPoint() {
-	//ev.Point.X = rand.Intn(ev.Size)
-	//ev.Point.Y = rand.Intn(ev.Size)
-	// ev.Point.X = 1
-	// ev.Point.Y = 1
-	/*for {
-		ev.Point2.X = rand.Intn(ev.Size)
-		ev.Point2.Y = rand.Intn(ev.Size)
-		if ev.Point2 != ev.Point {
-			break
+// EnumTrials sets Trials to AllTrials -- returns an error if there are none,
+// e.g., if they are all held out
+func (ev *ExEnv) EnumTrials() error {
+	ev.Trials = ev.AllTrials()
+	if len(ev.Trials) == 0 {
+		return fmt.Errorf("ExEnv: %v has no trials to enumerate -- check HoldOut: %v, MaxAngle: %d, Scales: %v", ev.Nm, ev.HoldOut.String(), ev.MaxAngle, ev.Scales)
+	}
+	return nil
+}
+
+// AllTrials returns every (start point, displacement) combination
+// that can be generated, in a fixed order: by transform in the order of
+// Xforms, then by scale factor in the order of Scales, then by displacement
+// in the order given by the sampler, and then by start point in row-major order
+func (ev *ExEnv) AllTrials() []PointTrial {
+	var trls []PointTrial
+	xfs := ev.Xforms
+	if len(xfs) == 0 {
+		xfs = []int{0}
+	}
+	scs := ev.Scales
+	if len(scs) == 0 {
+		scs = []float32{1}
+	}
+	for _, xf := range xfs {
+		for _, sc := range scs {
+			trls = ev.appendXformTrials(trls, xf, sc)
 		}
-	}*/
-	//maxDist1 := math.Hypot(float64(7-ev.Point.X), float64(7-ev.Point.Y)) // point 9, 9
-	//maxDist2 := math.Hypot(float64(ev.Point.X), float64(ev.Point.Y))     // point 0, 0
-	//ev.MaxDist = int(math.Min(maxDist1, maxDist2))
-	/* ev.MinDist = 5
-	ev.MaxDist = ev.Size - 1
-	dist := ev.MinDist + rand.Float32()*(float32(ev.MaxDist)-ev.MinDist)
-	ang := rand.Float32() * 360
-	ev.Point3.X = 8
-	ev.Point3.Y = 8
-	for {
-		ev.Point3.X = 8 + int(float64(dist*mat32.Cos(ang*math.Pi/180)))
-		ev.Point3.Y = 8 + int(float64(dist*mat32.Sin(ang*math.Pi/180)))
-		if !(ev.Point3.X == 8 && ev.Point3.Y == 8) { // point 3 cannot be 8, 8
-			break
+	}
+	return trls
+}
+
+// appendXformTrials returns trls with the trials for the given transform and scale factor added
+func (ev *ExEnv) appendXformTrials(trls []PointTrial, xf int, sc float32) []PointTrial {
+	for _, in := range ev.Disp.Disps(ev) {
+		disp := ev.XformSet[xf].Apply(in)
+		if !ev.DispOk(disp) || !ev.ScaleOk(geom.LatticeVec(disp), sc) {
+			continue
 		}
-	} */
-	for {
-		/* ev.Point3.X = rand.Intn(16)
-		ev.Point3.Y = rand.Intn(16)
-		if (ev.Point3.X <= 4 || ev.Point3.X >= 12) && (ev.Point3.Y <= 4 || ev.Point3.Y >= 12) {
-			break
-		} */
-		if rand.Intn(2) == 0 { //horizontal
-			ev.Point3.X = rand.Intn(16)
-			ev.Point3.Y = 8
-			if ev.Point3.X <= 4 || ev.Point3.X >= 12 {
-				break
+		minX, maxX := ev.startRange(disp.X)
+		minY, maxY := ev.startRange(disp.Y)
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				start := image.Point{x, y}
+				if ev.ExclHeld && ev.HoldOut.Contains(start, disp) {
+					continue
+				}
+				if !ev.OnGrid(geom.LatticeVec(start.Add(in))) { // the inputs too
+					continue
+				}
+				trls = append(trls, PointTrial{start, disp, in, xf, sc})
 			}
-		} else { //vertical
-			ev.Point3.X = 8
-			ev.Point3.Y = rand.Intn(16)
-			if ev.Point3.Y <= 4 || ev.Point3.Y >= 12 {
-				break
+		}
+	}
+	return trls
+}
+
+// Center returns the EgoInput coordinate that corresponds to zero
+// displacement -- the middle of the (2*Size-1) square ego grid
+func (ev *ExEnv) Center() int {
+	return ev.Size - 1
+}
+
+// DispRange returns the inclusive range of displacement magnitudes (in grid
+// units along each axis) that NewPoint samples from.  The lower bound comes
+// from MinDist, and the upper bound is the smaller of MaxDist and MaxDispAxis.
+func (ev *ExEnv) DispRange() (lo, hi int) {
+	lo = int(mat32.Ceil(ev.MinDist))
+	hi = ev.MaxDispAxis()
+	if ev.MaxDist < hi {
+		hi = ev.MaxDist
+	}
+	return
+}
+
+// DistNorm returns the distance by which the distance error of a trial is
+// normalized: the largest distance that DistPop encodes, which is set at
+// Config and so stays the same as a Curriculum changes MaxDist
+func (ev *ExEnv) DistNorm() float32 {
+	return ev.DistPop.Max
+}
+
+// MaxDispAxis returns the largest displacement along each axis: Size-1,
+// which keeps both Point and Point2 on the grid, or Size/2 if Wrap,
+// beyond which the shortest toroidal vector goes the other way around
+func (ev *ExEnv) MaxDispAxis() int {
+	if ev.Wrap {
+		return ev.Size / 2
+	}
+	return ev.Size - 1
+}
+
+// DispVecOk is DispOk for a real-valued displacement
+func (ev *ExEnv) DispVecOk(d mat32.Vec2) bool {
+	mx := float32(ev.MaxDispAxis())
+	if mat32.Abs(d.X) > mx || mat32.Abs(d.Y) > mx {
+		return false
+	}
+	dist := d.Length()
+	return dist > 0 && dist >= ev.MinDist && dist <= float32(ev.MaxDist) && ev.AngleOk(d) && ev.EgoOk(float64(dist))
+}
+
+// DispOk returns true if given displacement is within MaxDispAxis along
+// each axis, its length is within the MinDist..MaxDist band, and its
+// bearing is less than MaxAngle -- see also EgoOk
+func (ev *ExEnv) DispOk(d image.Point) bool {
+	mx := ev.MaxDispAxis()
+	if d.X < -mx || d.X > mx || d.Y < -mx || d.Y > mx {
+		return false
+	}
+	dist := math.Hypot(float64(d.X), float64(d.Y))
+	return dist > 0 && dist >= float64(ev.MinDist) && dist <= float64(ev.MaxDist) && ev.AngleOk(geom.LatticeVec(d)) && ev.EgoOk(dist)
+}
+
+// EgoOk returns true if a displacement of given length stays on EgoInput
+// when rotated by any heading -- this only limits the length if HeadingOn
+// with headings off the axes
+func (ev *ExEnv) EgoOk(dist float64) bool {
+	if !ev.HeadingOn || (ev.HeadingStep > 0 && math.Mod(float64(ev.HeadingStep), 90) == 0) {
+		return true
+	}
+	return dist <= float64(ev.MaxDispAxis())
+}
+
+// AngleOk returns true if the bearing of displacement d is less than MaxAngle
+func (ev *ExEnv) AngleOk(d mat32.Vec2) bool {
+	return ev.MaxAngle >= 360 || geom.Bearing(d) < float32(ev.MaxAngle)
+}
+
+// LatticeDisps returns all the lattice displacements for which DispOk is true,
+// in row-major order
+func (ev *ExEnv) LatticeDisps() []image.Point {
+	var ok []image.Point
+	mx := ev.MaxDispAxis()
+	for y := -mx; y <= mx; y++ {
+		for x := -mx; x <= mx; x++ {
+			d := image.Point{x, y}
+			if ev.DispOk(d) {
+				ok = append(ok, d)
 			}
 		}
+	}
+	return ok
+}
+
+// SampleStart samples a start point such that both it and the point
+// displaced from it by disp fall within the 0..Size-1 grid -- anywhere if Wrap
+func (ev *ExEnv) SampleStart(disp image.Point) image.Point {
+	minX, maxX := ev.startRange(disp.X)
+	minY, maxY := ev.startRange(disp.Y)
+	return image.Point{minX + ev.Rand.Intn(maxX-minX+1), minY + ev.Rand.Intn(maxY-minY+1)}
+}
+
+// SampleStartVec samples a start point such that both it and the point
+// displaced from it by disp fall within the 0..Size-1 grid -- uniformly over
+// the real-valued range if Continuous, and otherwise on the lattice
+func (ev *ExEnv) SampleStartVec(disp mat32.Vec2) mat32.Vec2 {
+	if !ev.Continuous {
+		return geom.LatticeVec(ev.SampleStart(geom.LatticePoint(disp)))
+	}
+	if ev.Wrap {
+		sz := float32(ev.Size)
+		return mat32.NewVec2(ev.Rand.Float32()*sz, ev.Rand.Float32()*sz)
+	}
+	mx := float32(ev.Size - 1)
+	minX, maxX := mat32.Max(0, -disp.X), mx-mat32.Max(0, disp.X)
+	minY, maxY := mat32.Max(0, -disp.Y), mx-mat32.Max(0, disp.Y)
+	return mat32.NewVec2(minX+ev.Rand.Float32()*(maxX-minX), minY+ev.Rand.Float32()*(maxY-minY))
+}
+
+// startRange returns the inclusive range of start coordinates for which
+// start + d stays within 0..Size-1 -- all of them if Wrap
+func (ev *ExEnv) startRange(d int) (min, max int) {
+	min = 0
+	max = ev.Size - 1
+	if ev.Wrap {
+		return
+	}
+	if d > 0 {
+		max -= d
+	} else {
+		min -= d
+	}
+	return
+}
+
+// SetSeed sets the Seed and restarts Rand from it
+func (ev *ExEnv) SetSeed(seed int64) {
+	ev.Seed = seed
+	ev.Rand = rand.New(rand.NewSource(seed))
+}
+
+// SetSampler sets the displacement sampler by name -- see DispSamplers
+func (ev *ExEnv) SetSampler(nm string) error {
+	ds, err := DispSamplerByName(nm)
+	if err != nil {
+		return err
+	}
+	ev.Sampler = nm
+	ev.Disp = ds
+	return nil
+}
+
+// SampleXform returns the index in XformSet of a random one of Xforms,
+// or 0 for none if there are no Xforms
+func (ev *ExEnv) SampleXform() int {
+	if len(ev.Xforms) == 0 {
+		return 0
+	}
+	return ev.Xforms[ev.Rand.Intn(len(ev.Xforms))]
+}
+
+// SampleDispVec samples a displacement from the sampler.  If Continuous,
+// the displacement is not rounded to the lattice: a ContDispSampler samples
+// it directly, and for the other samplers the length of the lattice
+// displacement is jittered by up to half a unit.
+func (ev *ExEnv) SampleDispVec() mat32.Vec2 {
+	if !ev.Continuous {
+		return geom.LatticeVec(ev.Disp.Sample(ev))
+	}
+	if cs, ok := ev.Disp.(ContDispSampler); ok {
+		return cs.SampleVec(ev)
+	}
+	d := geom.LatticeVec(ev.Disp.Sample(ev))
+	l := d.Length()
+	return d.MulScalar((l + ev.Rand.Float32() - 0.5) / l)
+}
+
+// XformVec returns displacement d transformed by XformSet[xf] -- rounded to
+// the lattice unless Continuous
+func (ev *ExEnv) XformVec(xf int, d mat32.Vec2) mat32.Vec2 {
+	if !ev.Continuous {
+		return geom.LatticeVec(ev.XformSet[xf].Apply(geom.LatticePoint(d)))
+	}
+	return ev.XformSet[xf].ApplyVec(d)
+}
+
+// OnGrid returns true if position p is within the grid -- always if Wrap
+func (ev *ExEnv) OnGrid(p mat32.Vec2) bool {
+	mx := float32(ev.Size - 1)
+	return ev.Wrap || p.X >= 0 && p.Y >= 0 && p.X <= mx && p.Y <= mx
+}
+
+// WrapPos returns position p wrapped around onto the 0..Size grid if Wrap,
+// and p otherwise
+func (ev *ExEnv) WrapPos(p mat32.Vec2) mat32.Vec2 {
+	if !ev.Wrap {
+		return p
+	}
+	sz := float32(ev.Size)
+	p.X -= sz * mat32.Floor(p.X/sz)
+	p.Y -= sz * mat32.Floor(p.Y/sz)
+	return p
+}
+
+// WrapVec returns the shortest toroidal vector equivalent to displacement d
+// if Wrap, and d otherwise
+func (ev *ExEnv) WrapVec(d mat32.Vec2) mat32.Vec2 {
+	if !ev.Wrap {
+		return d
+	}
+	sz := float32(ev.Size)
+	d.X -= sz * mat32.Round(d.X/sz)
+	d.Y -= sz * mat32.Round(d.Y/sz)
+	return d
+}
+
+// PointDisp returns the displacement from Point to Point2 -- the shortest
+// toroidal vector if Wrap
+func (ev *ExEnv) PointDisp() mat32.Vec2 {
+	return ev.WrapVec(ev.Point2.Sub(ev.Point))
+}
+
+// PopPos returns the Y, X center of the popcode for input point p,
+// offset at random by Corrupt.Jitter
+func (ev *ExEnv) PopPos(p mat32.Vec2) mat32.Vec2 {
+	pos := mat32.NewVec2(p.Y, p.X)
+	if ev.Corrupt.Jitter > 0 {
+		pos.X += ev.Corrupt.Jitter * float32(ev.Rand.NormFloat64())
+		pos.Y += ev.Corrupt.Jitter * float32(ev.Rand.NormFloat64())
+	}
+	return pos
+}
+
+// MaxScale returns the largest of the Scales, or 1 if there are none
+func (ev *ExEnv) MaxScale() float32 {
+	mx := float32(1)
+	if len(ev.Scales) > 0 {
+		mx = ev.Scales[0]
+	}
+	for _, s := range ev.Scales {
+		mx = mat32.Max(mx, s)
+	}
+	return mx
+}
+
+// SampleScale returns a scale factor chosen at random from Scales,
+// or 1 if there are none
+func (ev *ExEnv) SampleScale() float32 {
+	if len(ev.Scales) == 0 {
+		return 1
+	}
+	return ev.Scales[ev.Rand.Intn(len(ev.Scales))]
+}
+
+// ScaleOk returns true if displacement d scaled by given factor fits on the
+// grid, and is on the lattice unless Continuous, e.g., only even displacements
+// can be halved -- this only limits d in AnalogyTask, where the scaled
+// displacement goes from C to D
+func (ev *ExEnv) ScaleOk(d mat32.Vec2, sc float32) bool {
+	if ev.Task != AnalogyTask {
+		return true
+	}
+	sd := d.MulScalar(sc)
+	if !ev.Continuous && sd != geom.LatticeVec(geom.LatticePoint(sd)) {
+		return false
+	}
+	mx := float32(ev.MaxDispAxis())
+	return mat32.Abs(sd.X) <= mx && mat32.Abs(sd.Y) <= mx
+}
+
+// ScaleFits returns true if some displacement of the sampler is ScaleOk for
+// given scale factor, so that NewPoint can sample trials with it
+func (ev *ExEnv) ScaleFits(sc float32) bool {
+	for _, d := range ev.Disp.Disps(ev) {
+		if ev.ScaleOk(geom.LatticeVec(d), sc) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScales returns the scale factors in given comma-separated spec,
+// e.g., "0.5,2,3" -- empty for none
+func ParseScales(spec string) ([]float32, error) {
+	var scs []float32
+	for _, it := range strings.Split(spec, ",") {
+		it = strings.TrimSpace(it)
+		if it == "" {
+			continue
+		}
+		var s float32
+		if _, err := fmt.Sscanf(it, "%g", &s); err != nil || s <= 0 {
+			return nil, fmt.Errorf("ParseScales: scale: %v is not a positive number: %v", it, err)
+		}
+		scs = append(scs, s)
+	}
+	return scs, nil
+}
+
+// ScaleColName returns the suffix of the per-scale columns of the
+// TstEpcLog for given scale factor, e.g., x0.5
+func ScaleColName(sc float64) string {
+	return fmt.Sprintf("x%g", sc)
+}
+
+// ScaleColNames returns the names of the per-scale columns of the
+// TstEpcLog for given scale factors: DistErr_ and AnalogyErr_ for each
+func ScaleColNames(scs []float32) []string {
+	var nms []string
+	for _, sc := range scs {
+		nm := ScaleColName(float64(sc))
+		nms = append(nms, "DistErr_"+nm, "AnalogyErr_"+nm)
+	}
+	return nms
+}
+
+// SampleHeading sets HeadVal to a random heading if HeadingOn,
+// at a multiple of HeadingStep if set
+func (ev *ExEnv) SampleHeading() {
+	switch {
+	case !ev.HeadingOn:
+		ev.HeadVal = 0
+	case ev.HeadingStep > 0:
+		ev.HeadVal = float32(ev.Rand.Intn(int(360/ev.HeadingStep))) * ev.HeadingStep
+	default:
+		ev.HeadVal = ev.Rand.Float32() * 360
+	}
+}
+
+// EgoVec returns allocentric displacement d rotated into the frame of the
+// agent, i.e., by minus HeadVal -- the agent faces along the ego X axis
+func (ev *ExEnv) EgoVec(d mat32.Vec2) mat32.Vec2 {
+	if ev.HeadVal == 0 {
+		return d
+	}
+	return geom.RotateVec(d, -ev.HeadVal)
+}
+
+// NewPointTries is the number of times that NewPoint samples a trial before
+// it picks one of AllTrials instead
+const NewPointTries = 1000
+
+// NewPoint generates a new point and sets state accordingly.
+// The displacement is transformed by a random one of Xforms, if any,
+// and resampled if that takes it out of range, or takes the untransformed
+// one that the inputs encode off the grid.
+// If ExclHeld, resamples until the trial is not held out.
+// After NewPointTries, it picks one of AllTrials at random instead, so that
+// it cannot get stuck when few samples are valid.
+func (ev *ExEnv) NewPoint() {
+	for try := 0; try < NewPointTries; try++ {
+		xf := ev.SampleXform()
+		sc := ev.SampleScale()
+		in := ev.SampleDispVec()
+		disp := ev.XformVec(xf, in)
+		if !ev.DispVecOk(disp) || !ev.ScaleOk(disp, sc) {
+			continue
+		}
+		start := ev.SampleStartVec(disp)
+		if ev.ExclHeld && ev.HoldOut.Contains(geom.LatticePoint(start), geom.LatticePoint(disp)) {
+			continue
+		}
+		if !ev.OnGrid(start.Add(in)) {
+			continue
+		}
+		ev.Xform = xf
+		ev.ScaleVal = sc
+		ev.SampleHeading()
+		ev.SetXformPoints(start, in, disp)
+		return
+	}
+	trls := ev.AllTrials()
+	if len(trls) == 0 {
+		log.Printf("ExEnv: %v has no valid trials for NewPoint -- see Validate\n", ev.Nm)
+		return
+	}
+	ev.SetTrial(trls[ev.Rand.Intn(len(trls))])
+}
+
+// SetTrial sets the states for given trial, with a random heading
+func (ev *ExEnv) SetTrial(tr PointTrial) {
+	ev.Xform = tr.Xform
+	ev.ScaleVal = tr.Scale
+	ev.SampleHeading()
+	ev.SetXformPoints(geom.LatticeVec(tr.Start), geom.LatticeVec(tr.InDisp), geom.LatticeVec(tr.Disp))
+}
+
+// SetXformPoints sets the states for given start, untransformed displacement
+// in, and displacement disp, transformed from it by the current Xform: the
+// targets encode disp, as set by SetPoints, and if a transform applies, the
+// inputs encode in, on XfInputs -- see State
+func (ev *ExEnv) SetXformPoints(start, in, disp mat32.Vec2) {
+	ev.InDisp = in
+	ev.SetPoints(start, disp)
+	if ev.Xform > 0 {
+		ev.SetXfInputs()
+	}
+}
+
+// SetXfInputs encodes the untransformed displacement InDisp from Point on
+// XfInputs, for the states that depend on the displacement.  The other
+// inputs, e.g., Attn and AnalogyC, and the distractors, are the same for
+// the input and the target displacement.
+func (ev *ExEnv) SetXfInputs() {
+	if ev.XfInputs == nil {
+		ev.XfInputs = map[string]*etensor.Float32{}
+	}
+	xtsr := func(nm string, src *etensor.Float32) *etensor.Float32 {
+		xt, ok := ev.XfInputs[nm]
+		if !ok {
+			xt = &etensor.Float32{}
+			ev.XfInputs[nm] = xt
+		}
+		xt.CopyShapeFrom(src)
+		xt.CopyFrom(src)
+		return xt
+	}
+	c := float32(ev.Center())
+	p2 := ev.WrapPos(ev.Point.Add(ev.InDisp))
+	ego := ev.EgoVec(ev.InDisp)
+	dist, ang := ev.DistAng(ev.InDisp)
+	ev.DistPop.Encode(&xtsr("Distance", &ev.Distance).Values, dist, ev.NDistUnits, false)
+	ev.AnglePop.Encode(&xtsr("Angle", &ev.Angle).Values, ang, ev.NAngleUnits)
+	egoi := xtsr("EgoInput", &ev.EgoInput)
+	egoi.SetZeros()
+	ev.EgoInputPop.Encode(egoi, ev.PopPos(mat32.NewVec2(c+ego.X, c+ego.Y)), true)
+	allo := xtsr("AlloInput", &ev.AlloInput)
+	ev.AlloInputPop.Encode(allo, ev.PopPos(ev.Point), false)
+	ev.AlloInputPop.Encode(allo, ev.PopPos(p2), true)
+	for _, pt := range ev.Distractors {
+		ev.AlloInputPop.Encode(allo, ev.PopPos(pt), true)
+	}
+	if ev.NDistract > 0 {
+		feat := xtsr("AlloFeat", &ev.AlloFeat)
+		ev.AlloInputPop.Encode(feat.SubSpace([]int{0, ev.Feats[0]}), ev.PopPos(p2), false)
+	}
+}
 
+// DistAng returns the Distance and Angle values for displacement disp:
+// its length scaled by ScaleVal, and its bearing, relative to the heading
+// if EgoAngle
+func (ev *ExEnv) DistAng(disp mat32.Vec2) (dist, ang float32) {
+	dist, ang = geom.Polar(disp)
+	dist *= ev.ScaleVal
+	if ev.EgoAngle {
+		ang = geom.NormAngle(ang - ev.HeadVal)
 	}
-	xDist := ev.Point3.X - 8
-	yDist := ev.Point3.Y - 8
-	maxX := 0
-	minX := 0
-	maxY := 0
-	minY := 0
-	if xDist > 0 {
-		maxX = 8 - xDist
-		minX = 0
-	}
-	if xDist < 0 {
-		maxX = 8
-		minX = int(math.Abs(float64(xDist)))
-	}
-	if xDist == 0 {
-		minX = 0
-		maxX = 8
-	}
-	if yDist > 0 {
-		maxY = 8 - yDist
-		minY = 0
-	}
-	if yDist < 0 {
-		maxY = 8
-		minY = int(math.Abs(float64(yDist)))
-	}
-	if yDist == 0 {
-		minY = 0
-		maxY = 8
-	}
-	ev.Point.X = int(float32(minX) + rand.Float32()*float32((maxX-minX)))
-	ev.Point.Y = int(float32(minY) + rand.Float32()*float32((maxY-minY)))
-	ev.Point2.X = ev.Point.X + xDist
-	ev.Point2.Y = ev.Point.Y + yDist
-	//generate Point based on range above
-	hypotDist := math.Hypot(float64(ev.Point2.X-ev.Point.X), float64(ev.Point2.Y-ev.Point.Y))
-	xDistance := ev.Point2.X - ev.Point.X
-	yDistance := ev.Point2.Y - ev.Point.Y
-	//angle := math.Atan2(float64(ev.Point2.Y-ev.Point.Y), float64(ev.Point2.X-ev.Point.X))
-
-	ang0 := 0.0
-	ang360 := 0.0
-	if xDistance >= 0 && yDistance >= 0 {
-		ang0 = math.Atan2(float64(yDistance), float64(xDistance)) * 180 / math.Pi
-	} else if xDist < 0 && yDist >= 0 {
-		ang0 = math.Atan2(float64(yDistance), float64(xDistance)) * 180 / math.Pi
-	} else if xDist >= 0 && yDist < 0 {
-		ang360 = 360 - (math.Abs(math.Atan2(float64(yDistance), float64(xDistance))) * 180 / math.Pi)
-	} else { //xDist < 0 and yDist < 0
-		ang360 = 360 + (math.Atan2(float64(yDistance), float64(xDistance)) * 180 / math.Pi)
-	}
-	ang := float32(ang0 + ang360)
+	return
+}
+
+// SetPoints sets Point to given start, Point2 to start + disp (wrapped if Wrap), and Point3 to
+// the ego-centered displacement, and encodes all the states from them.
+// Held-out trials are matched on the nearest lattice points.
+func (ev *ExEnv) SetPoints(start, disp mat32.Vec2) {
+	c := float32(ev.Center())
+	ev.Point = start
+	ev.Point2 = ev.WrapPos(start.Add(disp))
+	ego := ev.EgoVec(disp)
+	ev.Point3 = mat32.NewVec2(c+ego.X, c+ego.Y)
+	ev.IsHeld = ev.HoldOut.Contains(geom.LatticePoint(start), geom.LatticePoint(disp))
+	dist, ang := ev.DistAng(disp)
 
 	ev.EgoInput.SetZeros()
 	ev.Attn.SetZeros()
 	ev.AlloInput.SetZeros()
-	ev.Attn.SetFloat([]int{ev.Point.Y, ev.Point.X}, 1)
+	ev.XformCue.SetZeros()
+	ev.XformCue.Values[ev.Xform] = 1
+	apt := geom.LatticePoint(ev.Point)
+	ev.Attn.SetFloat([]int{apt.Y, apt.X}, 1)
 	//ev.AlloInput.SetFloat([]int{ev.Point.Y, ev.Point.X}, 1)
 	//ev.AlloInput.SetFloat([]int{ev.Point2.Y, ev.Point2.X}, 1)
 	//ev.EgoInput.SetFloat([]int{ev.Size - 1, ev.Size - 1}, 1) //center point of input
 	//ev.EgoInput.SetFloat([]int{ev.Point3.Y, ev.Point3.X}, 1)
-	ev.DistPop.Encode(&ev.Distance.Values, float32(hypotDist), ev.NDistUnits, false)
-	ev.AnglePop.Encode(&ev.Angle.Values, float32(ang), ev.NAngleUnits)
-	ev.AttnPop.Encode(&ev.Attn, mat32.NewVec2(float32(ev.Point.Y), float32(ev.Point.X)), false)
+	ev.DistPop.Encode(&ev.Distance.Values, dist, ev.NDistUnits, false)
+	ev.AnglePop.Encode(&ev.Angle.Values, ang, ev.NAngleUnits)
+	ev.AttnPop.Encode(&ev.Attn, ev.PopPos(ev.Point), false)
 	//ev.EgoInputPop.Encode(&ev.EgoInput, mat32.NewVec2(float32(ev.Size-1), float32(ev.Size-1)), false)
-	ev.EgoInputPop.Encode(&ev.EgoInput, mat32.NewVec2(float32(ev.Point3.Y), float32(ev.Point3.X)), true)
-	ev.AlloInputPop.Encode(&ev.AlloInput, mat32.NewVec2(float32(ev.Point.Y), float32(ev.Point.X)), false)
-	ev.AlloInputPop.Encode(&ev.AlloInput, mat32.NewVec2(float32(ev.Point2.Y), float32(ev.Point2.X)), true)
-	ev.DistVal = float32(hypotDist)
-	ev.AngVal = float32(ang)
+	ev.EgoInputPop.Encode(&ev.EgoInput, ev.PopPos(ev.Point3), true)
+	ev.AlloInputPop.Encode(&ev.AlloInput, ev.PopPos(ev.Point), false)
+	ev.AlloInputPop.Encode(&ev.AlloInput, ev.PopPos(ev.Point2), true)
+	ev.DistVal = dist
+	ev.AngVal = ang
+	ev.Heading.SetZeros()
+	if ev.HeadingOn {
+		ev.HeadingPop.Encode(&ev.Heading.Values, ev.HeadVal, ev.NHeadUnits)
+	}
+	if len(ev.Scales) > 0 {
+		ev.ScalePop.Encode(&ev.ScaleCue.Values, ev.ScaleVal, ev.NScaleUnits, false)
+	}
+	if ev.NDistract > 0 {
+		ev.SetDistractors()
+	}
+	if ev.Task == AnalogyTask {
+		ev.SetAnalogy(ev.SampleStartVec(disp.MulScalar(ev.ScaleVal)))
+	}
+}
+
+// SetDistractors places NDistract distractor points at random on the grid,
+// apart from Point, Point2 and each other, and adds them to AlloInput.
+// Point2 and each distractor get a different random feature, and are
+// presented on the AlloFeat map for their feature, with the feature of
+// Point2 cued on FeatCue.  Each point is on a different lattice point,
+// and if Continuous the distractors are jittered by up to half a unit from it.
+func (ev *ExEnv) SetDistractors() {
+	ev.Distractors = ev.Distractors[:0]
+	used := map[image.Point]bool{geom.LatticePoint(ev.Point): true, geom.LatticePoint(ev.Point2): true}
+	for len(ev.Distractors) < ev.NDistract {
+		lp := image.Point{ev.Rand.Intn(ev.Size), ev.Rand.Intn(ev.Size)}
+		if used[lp] {
+			continue
+		}
+		used[lp] = true
+		pt := geom.LatticeVec(lp)
+		if ev.Continuous {
+			mx := float32(ev.Size - 1)
+			pt.X = mat32.Clamp(pt.X+ev.Rand.Float32()-0.5, 0, mx)
+			pt.Y = mat32.Clamp(pt.Y+ev.Rand.Float32()-0.5, 0, mx)
+		}
+		ev.Distractors = append(ev.Distractors, pt)
+		ev.AlloInputPop.Encode(&ev.AlloInput, ev.PopPos(pt), true)
+	}
+	ev.Feats = ev.Rand.Perm(ev.NFeats)[:ev.NDistract+1]
+	ev.AlloFeat.SetZeros()
+	ev.FeatCue.SetZeros()
+	ev.FeatCue.Values[ev.Feats[0]] = 1
+	for i, f := range ev.Feats {
+		pt := ev.Point2
+		if i > 0 {
+			pt = ev.Distractors[i-1]
+		}
+		ev.AlloInputPop.Encode(ev.AlloFeat.SubSpace([]int{0, f}), ev.PopPos(pt), false)
+	}
+}
+
+// DistractError returns whether the point at given decoded distance and
+// angle from Point (egocentric if EgoAngle) is closer to one of the Distractors than to Point2,
+// i.e., the wrong object was picked, and the distance in grid units from
+// that point to Point2, i.e., the metric error
+func (ev *ExEnv) DistractError(dist, ang float32) (picked bool, metric float32) {
+	if ev.EgoAngle {
+		ang += ev.HeadVal
+	}
+	dist /= ev.ScaleVal
+	pt := ev.Point.Add(geom.PolarVec(dist, ang))
+	metric = ev.WrapVec(ev.Point2.Sub(pt)).Length()
+	for _, d := range ev.Distractors {
+		if ev.WrapVec(d.Sub(pt)).Length() < metric {
+			return true, metric
+		}
+	}
+	return false, metric
+}
+
+// SetAnalogy sets PointC to given start of the target pair, and PointD to
+// the point that completes the analogy Point : Point2 :: PointC : PointD,
+// with the displacement scaled by ScaleVal,
+// and encodes AnalogyC, AnalogyD from them
+func (ev *ExEnv) SetAnalogy(c mat32.Vec2) {
+	ev.PointC = c
+	ev.PointD = ev.WrapPos(c.Add(ev.PointDisp().MulScalar(ev.ScaleVal)))
+	ev.AlloInputPop.Encode(&ev.AnalogyC, ev.PopPos(ev.PointC), false)
+	ev.AlloInputPop.Encode(&ev.AnalogyD, mat32.NewVec2(ev.PointD.Y, ev.PointD.X), false)
+}
+
+// AnalogyError returns the distance in grid units between the D point decoded
+// from given AnalogyD activity pattern and the actual PointD
+func (ev *ExEnv) AnalogyError(pat etensor.Tensor) (float32, error) {
+	d, err := ev.AlloInputPop.Decode(pat)
+	if err != nil {
+		return 0, err
+	}
+	return d.DistTo(mat32.NewVec2(ev.PointD.Y, ev.PointD.X)), nil
+}
+
+// NewPath starts a new PathTask sequence at a random origin --
+// anywhere on the grid if Continuous, and otherwise on the lattice --
+// with a new heading that is kept over the whole sequence
+func (ev *ExEnv) NewPath() {
+	ev.SampleHeading()
+	origin := geom.LatticeVec(image.Point{ev.Rand.Intn(ev.Size), ev.Rand.Intn(ev.Size)})
+	if ev.Continuous {
+		mx := float32(ev.Size - 1)
+		origin = mat32.NewVec2(ev.Rand.Float32()*mx, ev.Rand.Float32()*mx)
+	}
+	ev.Point = origin
+	ev.Point2 = origin
+}
+
+// PathOk returns true if from every point of the grid, one of the
+// displacements of the sampler keeps the position on the grid, so that
+// PathStep can always take a step -- always true if Wrap.  It only checks
+// the lattice: if Continuous, a position off the lattice can still have no
+// step, which PathStep reports.
+func (ev *ExEnv) PathOk() bool {
+	if ev.Wrap {
+		return true
+	}
+	disps := ev.Disp.Disps(ev)
+	grid := image.Rect(0, 0, ev.Size, ev.Size)
+	for y := 0; y < ev.Size; y++ {
+		for x := 0; x < ev.Size; x++ {
+			ok := false
+			for _, d := range disps {
+				if (image.Point{x, y}).Add(d).In(grid) {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// PathStep samples a displacement that keeps the cumulative position on the
+// grid, or wraps it around if Wrap, and moves the position by it.
+// After NewPointTries, it tries every one of the Disps in random order
+// instead, and returns false, leaving the position as is, if none of them
+// can be taken -- see PathOk, as checked by Validate.
+func (ev *ExEnv) PathStep() bool {
+	for try := 0; try < NewPointTries; try++ {
+		if ev.TakeStep(ev.SampleDispVec()) {
+			return true
+		}
+	}
+	disps := ev.Disp.Disps(ev)
+	for _, i := range ev.Rand.Perm(len(disps)) {
+		if ev.TakeStep(geom.LatticeVec(disps[i])) {
+			return true
+		}
+	}
+	log.Printf("ExEnv: %v has no path step from position %v that stays on the grid\n", ev.Nm, ev.Point2)
+	return false
+}
+
+// TakeStep moves the position of the path by displacement d and returns
+// true, unless that takes it off the grid
+func (ev *ExEnv) TakeStep(d mat32.Vec2) bool {
+	pos := ev.WrapPos(ev.Point2.Add(d))
+	if !ev.OnGrid(pos) {
+		return false
+	}
+	ev.SetPath(ev.Point, pos, d)
+	return true
+}
+
+// SetPath sets the states for a path from origin to current position pos,
+// with the displacement of the last step presented on EgoInput, in the
+// frame of the agent, and
+// the cumulative displacement from origin on AlloInput, Distance and Angle
+func (ev *ExEnv) SetPath(origin, pos, step mat32.Vec2) {
+	c := float32(ev.Center())
+	ev.SetPoints(origin, ev.WrapVec(pos.Sub(origin)))
+	ev.StepDisp = step
+	ego := ev.EgoVec(step)
+	ev.Point3 = mat32.NewVec2(c+ego.X, c+ego.Y)
+	ev.EgoInputPop.Encode(&ev.EgoInput, ev.PopPos(ev.Point3), false)
 }
 
 // Step is called to advance the environment state
 func (ev *ExEnv) Step() bool {
-	ev.Epoch.Same() // good idea to just reset all non-inner-most counters at start
-	ev.NewPoint()
+	ev.Epoch.Same()          // good idea to just reset all non-inner-most counters at start
+	if ev.Task == PathTask { // each Step is one step of the sequence
+		ev.Trial.Same()
+		if ev.Tick.Incr(); ev.Tick.Cur == 0 {
+			if ev.Trial.Incr() {
+				ev.Epoch.Incr()
+			}
+			ev.NewPath()
+		}
+		return ev.PathStep()
+	}
+	if ev.Enum {
+		if len(ev.Trials) == 0 { // see EnumTrials
+			return false
+		}
+		ev.SetTrial(ev.Trials[(ev.Trial.Cur+1)%len(ev.Trials)]) // Trial is incremented below
+	} else {
+		ev.NewPoint()
+	}
 	if ev.Trial.Incr() { // true if wraps around Max back to 0
 		ev.Epoch.Incr()
 	}
@@ -299,6 +1197,8 @@ func (ev *ExEnv) Counter(scale env.TimeScales) (cur, prv int, chg bool) {
 		return ev.Epoch.Query()
 	case env.Trial:
 		return ev.Trial.Query()
+	case env.Tick:
+		return ev.Tick.Query()
 	}
 	return -1, -1, false
 }
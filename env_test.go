@@ -5,7 +5,12 @@ import (
 	"image"
 	"math"
 	"math/rand"
+	"strings"
 	"testing"
+
+	"github.com/emer/emergent/params"
+	"github.com/goki/mat32"
+	"myEnv/geom"
 )
 
 func TestAngle(t *testing.T) {
@@ -22,21 +27,377 @@ func TestAngle(t *testing.T) {
 				break
 			}
 		}
-		xDist := Point2.X - Point.X
-		yDist := Point2.Y - Point.Y
-		ang0 := 0.0
-		ang360 := 0.0
-		if xDist >= 0 && yDist >= 0 {
-			ang0 = math.Atan2(float64(yDist), float64(xDist)) * 180 / math.Pi
-		} else if xDist < 0 && yDist >= 0 {
-			ang0 = math.Atan2(float64(yDist), float64(xDist)) * 180 / math.Pi
-		} else if xDist >= 0 && yDist < 0 {
-			ang360 = 360 - (math.Abs(math.Atan2(float64(yDist), float64(xDist))) * 180 / math.Pi)
-		} else { //xDist < 0 and yDist < 0
-			ang360 = 360 + (math.Atan2(float64(yDist), float64(xDist)) * 180 / math.Pi)
+		d := geom.LatticeVec(Point2.Sub(Point))
+		dist, ang := geom.Polar(d)
+		if ang < 0 || ang >= 360 {
+			t.Errorf("%v: bearing %g out of [0,360)", d, ang)
+		}
+		if geom.LatticePoint(geom.PolarVec(dist, ang)) != Point2.Sub(Point) {
+			t.Errorf("%v: dist %g, bearing %g do not map back to it", d, dist, ang)
+		}
+		fmt.Printf("%v %v %v %v \n", Point2, d.X, d.Y, ang)
+	}
+
+}
+
+func TestNewPointSizes(t *testing.T) {
+	for _, sz := range []int{7, 9, 13, 17} {
+		ev := ExEnv{}
+		ev.Config(sz, 10)
+		if err := ev.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		lo, hi := ev.DispRange()
+		for i := 0; i < 500; i++ {
+			ev.NewPoint()
+			for _, pt := range []mat32.Vec2{ev.Point, ev.Point2} {
+				if pt != geom.LatticeVec(geom.LatticePoint(pt)) || !geom.LatticePoint(pt).In(image.Rect(0, 0, sz, sz)) {
+					t.Errorf("size %d: point %v off the lattice", sz, pt)
+				}
+			}
+			d := geom.LatticePoint(ev.Point2.Sub(ev.Point))
+			mag := d.X + d.Y
+			if mag < 0 {
+				mag = -mag
+			}
+			if (d.X != 0 && d.Y != 0) || mag < lo || mag > hi {
+				t.Errorf("size %d: displacement %v not axis-aligned in %d..%d", sz, d, lo, hi)
+			}
+			if ev.Point3 != geom.LatticeVec(d.Add(image.Point{ev.Center(), ev.Center()})) {
+				t.Errorf("size %d: Point3 %v not centered displacement %v", sz, ev.Point3, d)
+			}
+		}
+	}
+}
+
+func TestSeedReproducible(t *testing.T) {
+	ev1 := ExEnv{}
+	ev1.Config(9, 10)
+	ev1.SetSampler("lattice")
+	ev1.SetSeed(42)
+	ev2 := ExEnv{}
+	ev2.Config(9, 10)
+	ev2.SetSampler("lattice")
+	ev2.SetSeed(42)
+	for i := 0; i < 100; i++ {
+		ev1.NewPoint()
+		ev2.NewPoint()
+		if ev1.Point != ev2.Point || ev1.Point2 != ev2.Point2 {
+			t.Fatalf("trial %d: same seed gave %v %v vs. %v %v", i, ev1.Point, ev1.Point2, ev2.Point, ev2.Point2)
+		}
+	}
+}
+
+func TestAnalogy(t *testing.T) {
+	ev := ExEnv{}
+	ev.Task = AnalogyTask
+	ev.Config(9, 10)
+	ev.SetSampler("lattice")
+	for i := 0; i < 200; i++ {
+		ev.NewPoint()
+		if ev.PointD.Sub(ev.PointC) != ev.Point2.Sub(ev.Point) {
+			t.Errorf("C %v -> D %v is not A %v -> B %v", ev.PointC, ev.PointD, ev.Point, ev.Point2)
+		}
+		if !geom.LatticePoint(ev.PointD).In(image.Rect(0, 0, ev.Size, ev.Size)) {
+			t.Errorf("D %v out of range", ev.PointD)
+		}
+		derr, err := ev.AnalogyError(&ev.AnalogyD)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if derr > 0.5 {
+			t.Errorf("D %v decoded from its own target with error %g", ev.PointD, derr)
+		}
+	}
+}
+
+func TestPathSteps(t *testing.T) {
+	ev := ExEnv{}
+	ev.Task = PathTask
+	ev.Config(9, 10)
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ev.Init(0)
+	var cum mat32.Vec2
+	for i := 0; i < 20*ev.NSteps; i++ {
+		ev.Step()
+		if ev.Tick.Cur != i%ev.NSteps || ev.Trial.Cur != (i/ev.NSteps)%ev.Trial.Max {
+			t.Fatalf("step %d: tick %d trial %d", i, ev.Tick.Cur, ev.Trial.Cur)
+		}
+		if ev.Tick.Cur == 0 {
+			cum = mat32.Vec2{}
+		}
+		cum = cum.Add(ev.StepDisp)
+		if ev.Point2.Sub(ev.Point) != cum {
+			t.Errorf("step %d: position %v from origin %v is not the sum of steps %v", i, ev.Point2, ev.Point, cum)
+		}
+		if !geom.LatticePoint(ev.Point2).In(image.Rect(0, 0, ev.Size, ev.Size)) {
+			t.Errorf("step %d: position %v off the grid", i, ev.Point2)
+		}
+		if ev.Point3 != ev.StepDisp.AddScalar(float32(ev.Center())) {
+			t.Errorf("step %d: Point3 %v is not the centered step %v", i, ev.Point3, ev.StepDisp)
+		}
+	}
+}
+
+func TestDistractors(t *testing.T) {
+	ev := ExEnv{}
+	ev.NDistract = 3
+	ev.Config(9, 10)
+	ev.SetSampler("lattice")
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		ev.NewPoint()
+		used := map[mat32.Vec2]bool{ev.Point: true, ev.Point2: true}
+		for _, d := range ev.Distractors {
+			if used[d] {
+				t.Errorf("distractor %v overlaps another object", d)
+			}
+			used[d] = true
+		}
+		if len(ev.Distractors) != ev.NDistract || len(ev.Feats) != ev.NDistract+1 {
+			t.Fatalf("%d distractors, %d features", len(ev.Distractors), len(ev.Feats))
+		}
+		if ev.FeatCue.Values[ev.Feats[0]] != 1 {
+			t.Errorf("feature %d of Point2 not cued", ev.Feats[0])
+		}
+		if picked, metric := ev.DistractError(ev.DistVal, ev.AngVal); picked || metric > 0.01 {
+			t.Errorf("exact Distance, Angle: picked: %v metric: %g", picked, metric)
+		}
+		d := ev.Distractors[0].Sub(ev.Point)
+		dist, ang := geom.Polar(d)
+		if picked, _ := ev.DistractError(dist, ang); !picked {
+			t.Errorf("Distance, Angle to distractor %v not picked", ev.Distractors[0])
 		}
-		ang := ang0 + ang360
-		fmt.Printf("%v %v %v %v %v %v \n", Point2, xDist, yDist, ang, ang0, ang360)
 	}
+}
 
+func TestContinuous(t *testing.T) {
+	for _, ds := range []string{"axis", "angle"} {
+		ev := ExEnv{}
+		ev.Continuous = true
+		ev.NDistract = 2
+		ev.Config(9, 10)
+		ev.SetSampler(ds)
+		if err := ev.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		mx := float32(ev.Size - 1)
+		offLattice := 0
+		for i := 0; i < 500; i++ {
+			ev.NewPoint()
+			for _, pt := range append([]mat32.Vec2{ev.Point, ev.Point2}, ev.Distractors...) {
+				if pt.X < 0 || pt.Y < 0 || pt.X > mx || pt.Y > mx {
+					t.Errorf("%v: point %v off the grid", ds, pt)
+				}
+			}
+			d := ev.Point2.Sub(ev.Point)
+			if !ev.DispVecOk(d) {
+				t.Errorf("%v: displacement %v out of band", ds, d)
+			}
+			if mat32.Abs(ev.DistVal-d.Length()) > 1.0e-4 {
+				t.Errorf("%v: DistVal %g is not the length of %v", ds, ev.DistVal, d)
+			}
+			if d != geom.LatticeVec(geom.LatticePoint(d)) {
+				offLattice++
+			}
+		}
+		if offLattice == 0 {
+			t.Errorf("%v: all displacements on the lattice", ds)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	ev := ExEnv{}
+	ev.Wrap = true
+	ev.Config(9, 10)
+	ev.SetSampler("lattice")
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	grid := image.Rect(0, 0, ev.Size, ev.Size)
+	wrapped := 0
+	for i := 0; i < 500; i++ {
+		ev.NewPoint()
+		if !geom.LatticePoint(ev.Point).In(grid) || !geom.LatticePoint(ev.Point2).In(grid) {
+			t.Errorf("points %v %v off the grid", ev.Point, ev.Point2)
+		}
+		d := ev.PointDisp()
+		if !ev.DispOk(geom.LatticePoint(d)) {
+			t.Errorf("toroidal displacement %v out of band", d)
+		}
+		if mat32.Abs(ev.DistVal-d.Length()) > 1.0e-4 {
+			t.Errorf("DistVal %g is not the length of %v", ev.DistVal, d)
+		}
+		if ev.Point2 != ev.Point.Add(d) {
+			wrapped++
+		}
+	}
+	if wrapped == 0 {
+		t.Errorf("no trials wrapped around the edges")
+	}
+	ev.Enum = true
+	ev.Init(0)
+	if nd := len(ev.Disp.Disps(&ev)); ev.Trial.Max != nd*ev.Size*ev.Size {
+		t.Errorf("%d trials for %d displacements from every start point", ev.Trial.Max, nd)
+	}
+}
+
+func TestHeading(t *testing.T) {
+	ev := ExEnv{}
+	ev.HeadingOn = true
+	ev.EgoAngle = true
+	ev.Config(9, 10)
+	ev.SetSampler("angle")
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	c := float32(ev.Center())
+	for i := 0; i < 200; i++ {
+		ev.NewPoint()
+		d := ev.PointDisp()
+		ego := ev.Point3.SubScalar(c)
+		if mat32.Abs(ego.Length()-d.Length()) > 1.0e-4 {
+			t.Errorf("ego vector %v is not the length of %v", ego, d)
+		}
+		hd := ev.HeadVal
+		ev.HeadVal = -hd
+		if back := ev.EgoVec(ego); back.DistTo(d) > 1.0e-4 {
+			t.Errorf("ego vector %v rotated back by heading %g is %v, not %v", ego, hd, back, d)
+		}
+		ev.HeadVal = hd
+		if ang := geom.Bearing(ego); geom.AngleDist(ang, ev.AngVal) > 1.0e-3 {
+			t.Errorf("ego AngVal %g is not the bearing %g of ego vector %v", ev.AngVal, ang, ego)
+		}
+		if _, mx, _, _ := ev.Heading.Range(); mx <= 0 {
+			t.Errorf("heading %g is not encoded on Heading", ev.HeadVal)
+		}
+	}
+	ev.HeadingStep = 90
+	for i := 0; i < 50; i++ {
+		ev.NewPoint()
+		if math.Mod(float64(ev.HeadVal), 90) != 0 {
+			t.Errorf("heading %g is not a multiple of 90", ev.HeadVal)
+		}
+		ego := ev.Point3.SubScalar(c)
+		if geom.LatticeVec(geom.LatticePoint(ego)).DistTo(ego) > 1.0e-4 {
+			t.Errorf("ego vector %v is off the lattice with heading %g", ego, ev.HeadVal)
+		}
+	}
+}
+
+func TestScales(t *testing.T) {
+	ev := ExEnv{}
+	ev.Task = AnalogyTask
+	ev.Scales = []float32{0.5, 2, 3}
+	ev.Config(9, 10)
+	ev.SetSampler("lattice")
+	if err := ev.Validate(); err == nil {
+		t.Errorf("expected error for scale 3 of displacements of at least MinDist %g", ev.MinDist)
+	}
+	ev.MinDist = 2 // 3 x 2 fits in 8
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if ev.DistPop.Max < float32(ev.MaxDist)*3 {
+		t.Errorf("DistPop.Max %g does not cover scaled distances up to %d", ev.DistPop.Max, ev.MaxDist*3)
+	}
+	mx := float32(ev.Size - 1)
+	for i := 0; i < 300; i++ {
+		ev.NewPoint()
+		d := ev.PointDisp()
+		if ev.PointD.Sub(ev.PointC).DistTo(d.MulScalar(ev.ScaleVal)) > 1.0e-4 {
+			t.Errorf("C %v to D %v is not %g x %v", ev.PointC, ev.PointD, ev.ScaleVal, d)
+		}
+		if mat32.Abs(ev.DistVal-ev.ScaleVal*d.Length()) > 1.0e-4 {
+			t.Errorf("DistVal %g is not %g x the length of %v", ev.DistVal, ev.ScaleVal, d)
+		}
+		if ev.PointD.X < 0 || ev.PointD.Y < 0 || ev.PointD.X > mx || ev.PointD.Y > mx {
+			t.Errorf("D %v off the grid for scale %g", ev.PointD, ev.ScaleVal)
+		}
+		if ev.PointD != geom.LatticeVec(geom.LatticePoint(ev.PointD)) {
+			t.Errorf("D %v off the lattice for scale %g", ev.PointD, ev.ScaleVal)
+		}
+	}
+	ev.Enum = true
+	ev.Init(0)
+	nsc := map[float32]int{}
+	for _, tr := range ev.Trials {
+		nsc[tr.Scale]++
+	}
+	for _, sc := range ev.Scales {
+		if nsc[sc] == 0 {
+			t.Errorf("no enumerated trials with scale %g", sc)
+		}
+	}
+	if nsc[0.5] <= nsc[3] {
+		t.Errorf("scale 3 should leave fewer displacements that fit than 0.5: %v", nsc)
+	}
+}
+
+func TestScaleColNames(t *testing.T) {
+	scs, err := ParseScales("0.5, 2,3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"DistErr_x0.5", "AnalogyErr_x0.5", "DistErr_x2", "AnalogyErr_x2", "DistErr_x3", "AnalogyErr_x3"}
+	nms := ScaleColNames(scs)
+	if strings.Join(nms, ",") != strings.Join(want, ",") {
+		t.Errorf("columns %v for scales %v, want %v", nms, scs, want)
+	}
+	if scs, _ = ParseScales(""); len(ScaleColNames(scs)) != 0 {
+		t.Errorf("columns %v without scales", ScaleColNames(scs))
+	}
+}
+
+func TestEnvParams(t *testing.T) {
+	ev := ExEnv{}
+	ev.Config(9, 10)
+	sh := params.Sheet{
+		{Sel: "ExEnv", Params: params.Params{
+			"ExEnv.NAngleUnits": "36",
+			"ExEnv.DistPop.Max": "20",
+		}},
+	}
+	if _, err := sh.Apply(&ev, false); err != nil {
+		t.Fatal(err)
+	}
+	ev.ConfigStates()
+	if ev.Angle.Len() != 36 || ev.DistPop.Max != 20 {
+		t.Errorf("Angle has %d units, DistPop.Max: %g", ev.Angle.Len(), ev.DistPop.Max)
+	}
+	for _, el := range ev.States() {
+		if el.Name == "Angle" && el.Shape[0] != 36 {
+			t.Errorf("Angle state has shape %v", el.Shape)
+		}
+	}
+}
+
+// farDisps is a sampler whose only displacement leaves the grid from anywhere
+type farDisps struct {
+	LatticeSampler
+}
+
+func (ds *farDisps) Sample(ev *ExEnv) image.Point  { return image.Point{ev.Size, 0} }
+func (ds *farDisps) Disps(ev *ExEnv) []image.Point { return []image.Point{{ev.Size, 0}} }
+
+func TestPathNoStep(t *testing.T) {
+	ev := ExEnv{}
+	ev.Task = PathTask
+	ev.Config(9, 10)
+	ev.Disp = &farDisps{}
+	if err := ev.Validate(); err == nil {
+		t.Errorf("expected error for a PathTask with no step on the grid")
+	}
+	ev.Init(0)
+	if ev.Step() {
+		t.Errorf("Step took a step off the grid, to %v", ev.Point2)
+	}
+	if ev.Point2 != ev.Point {
+		t.Errorf("position %v moved from origin %v", ev.Point2, ev.Point)
+	}
 }
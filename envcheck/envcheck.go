@@ -0,0 +1,80 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package envcheck checks that the States of an env match the Input and
+// Target layers of a network -- shared by the sims of both phases.
+package envcheck
+
+import (
+	"fmt"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/env"
+)
+
+// Layers returns an error unless the States of given env match the
+// Input and Target layers of the network one to one, by name and by shape,
+// ignoring dimensions of size 1 -- otherwise ApplyInputs would silently
+// skip the layers that do not match
+func Layers(net emer.Network, en env.Env) error {
+	ed, ok := en.(env.EnvDesc)
+	if !ok {
+		return fmt.Errorf("envcheck.Layers: %v does not describe its States", en.Name())
+	}
+	enms := map[string]bool{}
+	for _, el := range ed.States() {
+		enms[el.Name] = true
+		ly := net.LayerByName(el.Name)
+		if ly == nil {
+			return fmt.Errorf("envcheck.Layers: %v state: %v has no layer of that name in the network", en.Name(), el.Name)
+		}
+		if !IOLayer(ly) {
+			return fmt.Errorf("envcheck.Layers: %v state: %v is for a %v layer, not Input or Target", en.Name(), el.Name, ly.Type())
+		}
+		if !SameUnitShape(el.Shape, ly.Shape().Shapes()) {
+			return fmt.Errorf("envcheck.Layers: %v state: %v has shape %v, but the layer has shape %v", en.Name(), el.Name, el.Shape, ly.Shape().Shapes())
+		}
+	}
+	for li := 0; li < net.NLayers(); li++ {
+		ly := net.Layer(li)
+		if IOLayer(ly) && !enms[ly.Name()] {
+			return fmt.Errorf("envcheck.Layers: %v layer: %v has no state of that name in %v", ly.Type(), ly.Name(), en.Name())
+		}
+	}
+	return nil
+}
+
+// IOLayer returns true if given layer gets inputs or targets from the env
+func IOLayer(ly emer.Layer) bool {
+	switch ly.Type() {
+	case emer.Input, emer.Target, emer.Compare:
+		return true
+	}
+	return false
+}
+
+// SameUnitShape returns true if given shapes are the same after leaving out
+// dimensions of size 1, e.g., {16} and {1, 16}
+func SameUnitShape(a, b []int) bool {
+	var sa, sb []int
+	for _, d := range a {
+		if d != 1 {
+			sa = append(sa, d)
+		}
+	}
+	for _, d := range b {
+		if d != 1 {
+			sb = append(sb, d)
+		}
+	}
+	if len(sa) != len(sb) {
+		return false
+	}
+	for i := range sa {
+		if sa[i] != sb[i] {
+			return false
+		}
+	}
+	return true
+}
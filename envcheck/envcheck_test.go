@@ -0,0 +1,74 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envcheck
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+func TestSameUnitShape(t *testing.T) {
+	tests := []struct {
+		a, b []int
+		same bool
+	}{
+		{[]int{16}, []int{1, 16}, true},
+		{[]int{4, 4}, []int{4, 4, 1}, true},
+		{[]int{4, 4}, []int{16}, false},
+		{[]int{4, 4}, []int{4, 5}, false},
+	}
+	for _, tt := range tests {
+		if same := SameUnitShape(tt.a, tt.b); same != tt.same {
+			t.Errorf("SameUnitShape(%v, %v): %v, want %v", tt.a, tt.b, same, tt.same)
+		}
+	}
+}
+
+// tableEnv returns a FixedTable env with a state for each of the given
+// columns, each with the shape that follows its name
+func tableEnv(cols map[string][]int) *env.FixedTable {
+	sch := etable.Schema{}
+	for nm, shp := range cols {
+		sch = append(sch, etable.Column{nm, etensor.FLOAT32, shp, nil})
+	}
+	dt := &etable.Table{}
+	dt.SetFromSchema(sch, 1)
+	ev := &env.FixedTable{Nm: "TestEnv"}
+	ev.Table = etable.NewIdxView(dt)
+	return ev
+}
+
+func TestLayers(t *testing.T) {
+	net := &leabra.Network{}
+	net.InitName(net, "TestNet")
+	net.AddLayer2D("Input", 4, 4, emer.Input)
+	net.AddLayer2D("Hidden", 3, 3, emer.Hidden)
+	net.AddLayer2D("Output", 1, 2, emer.Target)
+
+	if err := Layers(net, tableEnv(map[string][]int{"Input": {4, 4}, "Output": {2}})); err != nil {
+		t.Errorf("matching env: %v", err)
+	}
+	tests := []struct {
+		cols map[string][]int
+		err  string
+	}{
+		{map[string][]int{"Input": {4, 4}, "Out": {2}}, "no layer of that name"},
+		{map[string][]int{"Input": {4, 4}}, "no state of that name"},
+		{map[string][]int{"Input": {4, 4}, "Output": {2}, "Hidden": {3, 3}}, "not Input or Target"},
+		{map[string][]int{"Input": {2, 8}, "Output": {2}}, "has shape"},
+	}
+	for _, tt := range tests {
+		err := Layers(net, tableEnv(tt.cols))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%v: error %v, want one with %q", tt.cols, err, tt.err)
+		}
+	}
+}
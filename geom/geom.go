@@ -0,0 +1,81 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package geom defines the geometry conventions shared by the environments
+// and the stats of both phases: displacements are X, Y vectors in grid units,
+// and bearings are in degrees, counter-clockwise from the X axis, in the range
+// [0,360).  Computations are done in float64, so that round trips are exact
+// to float32 precision.
+package geom
+
+import (
+	"image"
+	"math"
+
+	"github.com/goki/mat32"
+)
+
+// Bearing returns the bearing of displacement d in [0,360) -- 0 for zero d
+func Bearing(d mat32.Vec2) float32 {
+	return NormAngle(float32(math.Atan2(float64(d.Y), float64(d.X)) * 180 / math.Pi))
+}
+
+// Polar returns the length and the bearing of displacement d
+func Polar(d mat32.Vec2) (dist, bearing float32) {
+	return float32(math.Hypot(float64(d.X), float64(d.Y))), Bearing(d)
+}
+
+// PolarVec returns the displacement of given length and bearing
+func PolarVec(dist, bearing float32) mat32.Vec2 {
+	a := float64(bearing) * math.Pi / 180
+	return mat32.NewVec2(float32(float64(dist)*math.Cos(a)), float32(float64(dist)*math.Sin(a)))
+}
+
+// NormAngle returns angle a in degrees wrapped into [0,360)
+func NormAngle(a float32) float32 {
+	n := math.Mod(float64(a), 360)
+	if n < 0 {
+		n += 360
+	}
+	if f := float32(n); f < 360 {
+		return f
+	}
+	return 0 // a tiny negative a rounds up to 360
+}
+
+// AngleDiff returns the signed circular difference a - b in degrees,
+// in [-180,180) -- positive if a is counter-clockwise of b
+func AngleDiff(a, b float32) float32 {
+	return NormAngle(a-b+180) - 180
+}
+
+// AngleDist returns the absolute circular difference between a and b in
+// degrees, in [0,180]
+func AngleDist(a, b float32) float32 {
+	return mat32.Abs(AngleDiff(a, b))
+}
+
+// RotateVec returns d rotated counter-clockwise by given angle in degrees
+func RotateVec(d mat32.Vec2, angle float32) mat32.Vec2 {
+	a := float64(angle) * math.Pi / 180
+	x, y := float64(d.X), float64(d.Y)
+	return mat32.NewVec2(float32(x*math.Cos(a)-y*math.Sin(a)), float32(x*math.Sin(a)+y*math.Cos(a)))
+}
+
+// ReflectVec returns d reflected across the axis at given bearing in degrees
+func ReflectVec(d mat32.Vec2, axis float32) mat32.Vec2 {
+	a := 2 * float64(axis) * math.Pi / 180
+	x, y := float64(d.X), float64(d.Y)
+	return mat32.NewVec2(float32(x*math.Cos(a)+y*math.Sin(a)), float32(x*math.Sin(a)-y*math.Cos(a)))
+}
+
+// LatticeVec returns lattice point p as a real-valued vector
+func LatticeVec(p image.Point) mat32.Vec2 {
+	return mat32.NewVec2(float32(p.X), float32(p.Y))
+}
+
+// LatticePoint returns the lattice point nearest to v -- halves round away from zero
+func LatticePoint(v mat32.Vec2) image.Point {
+	return image.Point{int(mat32.Round(v.X)), int(mat32.Round(v.Y))}
+}
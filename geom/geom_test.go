@@ -0,0 +1,78 @@
+package geom
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goki/mat32"
+)
+
+func TestBearing(t *testing.T) {
+	cases := []struct {
+		d   mat32.Vec2
+		ang float32
+	}{
+		{mat32.NewVec2(1, 0), 0},
+		{mat32.NewVec2(1, 1), 45},
+		{mat32.NewVec2(0, 2), 90},
+		{mat32.NewVec2(-3, 0), 180},
+		{mat32.NewVec2(-1, -1), 225},
+		{mat32.NewVec2(0, -1), 270},
+		{mat32.NewVec2(1, -1), 315},
+		{mat32.NewVec2(1, -1.0e-9), 0},
+	}
+	for _, c := range cases {
+		dist, ang := Polar(c.d)
+		if mat32.Abs(ang-c.ang) > 1.0e-4 {
+			t.Errorf("bearing of %v: %g, not %g", c.d, ang, c.ang)
+		}
+		if back := PolarVec(dist, ang); back.DistTo(c.d) > 1.0e-4 {
+			t.Errorf("%v to polar and back is %v", c.d, back)
+		}
+	}
+}
+
+func TestAngleDiff(t *testing.T) {
+	cases := []struct {
+		a, b, diff float32
+	}{
+		{10, 350, 20},
+		{350, 10, -20},
+		{90, 90, 0},
+		{0, 180, -180},
+		{720, 45, -45},
+		{-90, 90, -180},
+	}
+	for _, c := range cases {
+		if d := AngleDiff(c.a, c.b); mat32.Abs(d-c.diff) > 1.0e-4 {
+			t.Errorf("AngleDiff(%g, %g): %g, not %g", c.a, c.b, d, c.diff)
+		}
+		if d := AngleDist(c.a, c.b); mat32.Abs(d-mat32.Abs(c.diff)) > 1.0e-4 {
+			t.Errorf("AngleDist(%g, %g): %g, not %g", c.a, c.b, d, mat32.Abs(c.diff))
+		}
+	}
+	for _, a := range []float32{-360, -1.0e-6, 0, 359.99, 360, 725} {
+		if n := NormAngle(a); n < 0 || n >= 360 {
+			t.Errorf("NormAngle(%g): %g out of [0,360)", a, n)
+		}
+	}
+}
+
+func TestRotateLattice(t *testing.T) {
+	d := mat32.NewVec2(3, 1)
+	if r := LatticePoint(RotateVec(d, 90)); r != (image.Point{-1, 3}) {
+		t.Errorf("%v rotated by 90: %v", d, r)
+	}
+	if r := LatticePoint(ReflectVec(d, 0)); r != (image.Point{3, -1}) {
+		t.Errorf("%v reflected across the X axis: %v", d, r)
+	}
+	if r := LatticePoint(ReflectVec(d, 45)); r != (image.Point{1, 3}) {
+		t.Errorf("%v reflected across the diagonal: %v", d, r)
+	}
+	if p := LatticePoint(mat32.NewVec2(-0.5, 2.5)); p != (image.Point{-1, 3}) {
+		t.Errorf("halves do not round away from zero: %v", p)
+	}
+	if v := LatticeVec(image.Point{2, -4}); v != mat32.NewVec2(2, -4) {
+		t.Errorf("LatticeVec: %v", v)
+	}
+}
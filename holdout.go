@@ -0,0 +1,124 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"myEnv/geom"
+)
+
+// AngleSector is a range of bearings in degrees, going counter-clockwise
+// from Min to Max -- Max can be less than Min to wrap around 0
+type AngleSector struct {
+	Min float32 `desc:"starting bearing of the sector, in degrees"`
+	Max float32 `desc:"ending bearing of the sector, in degrees -- exclusive"`
+}
+
+// Contains returns true if given bearing in 0..360 is within the sector
+func (as *AngleSector) Contains(ang float32) bool {
+	if as.Min <= as.Max {
+		return ang >= as.Min && ang < as.Max
+	}
+	return ang >= as.Min || ang < as.Max
+}
+
+// HoldOut defines the trials that are held out of training, to test whether
+// relations generalize to displacements, start positions and directions
+// that were never trained.  A trial is held out if any of its parts matches.
+type HoldOut struct {
+	Disps   []image.Point     `desc:"displacement vectors (Point2 - Point) that are held out"`
+	Regions []image.Rectangle `desc:"regions of the grid where start points (Point) are held out -- Max is exclusive"`
+	Sectors []AngleSector     `desc:"sectors of displacement bearings that are held out"`
+}
+
+// IsEmpty returns true if nothing is held out
+func (ho *HoldOut) IsEmpty() bool {
+	return len(ho.Disps) == 0 && len(ho.Regions) == 0 && len(ho.Sectors) == 0
+}
+
+// Contains returns true if the trial with given start point and displacement
+// is held out
+func (ho *HoldOut) Contains(start, disp image.Point) bool {
+	for _, d := range ho.Disps {
+		if d == disp {
+			return true
+		}
+	}
+	for _, r := range ho.Regions {
+		if start.In(r) {
+			return true
+		}
+	}
+	if len(ho.Sectors) > 0 {
+		ang := geom.Bearing(geom.LatticeVec(disp))
+		for i := range ho.Sectors {
+			if ho.Sectors[i].Contains(ang) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String returns the held-out items in the spec format read by ParseHoldOut
+func (ho *HoldOut) String() string {
+	var its []string
+	for _, d := range ho.Disps {
+		its = append(its, fmt.Sprintf("disp:%d,%d", d.X, d.Y))
+	}
+	for _, r := range ho.Regions {
+		its = append(its, fmt.Sprintf("region:%d,%d,%d,%d", r.Min.X, r.Min.Y, r.Max.X, r.Max.Y))
+	}
+	for _, s := range ho.Sectors {
+		its = append(its, fmt.Sprintf("sector:%g,%g", s.Min, s.Max))
+	}
+	return strings.Join(its, ";")
+}
+
+// ParseHoldOut parses a hold-out spec of semicolon-separated items, each being
+// one of:
+//
+//	disp:x,y -- displacement vector
+//	region:x0,y0,x1,y1 -- start region, x1,y1 exclusive
+//	sector:min,max -- bearing sector in degrees
+//
+// e.g., "disp:4,0;region:0,0,3,3;sector:30,60"
+func ParseHoldOut(spec string) (HoldOut, error) {
+	ho := HoldOut{}
+	for _, it := range strings.Split(spec, ";") {
+		it = strings.TrimSpace(it)
+		if it == "" {
+			continue
+		}
+		kv := strings.SplitN(it, ":", 2)
+		if len(kv) != 2 {
+			return ho, fmt.Errorf("ParseHoldOut: item: %v is not of the form kind:values", it)
+		}
+		var err error
+		switch kv[0] {
+		case "disp":
+			var d image.Point
+			_, err = fmt.Sscanf(kv[1], "%d,%d", &d.X, &d.Y)
+			ho.Disps = append(ho.Disps, d)
+		case "region":
+			var r image.Rectangle
+			_, err = fmt.Sscanf(kv[1], "%d,%d,%d,%d", &r.Min.X, &r.Min.Y, &r.Max.X, &r.Max.Y)
+			ho.Regions = append(ho.Regions, r)
+		case "sector":
+			var s AngleSector
+			_, err = fmt.Sscanf(kv[1], "%g,%g", &s.Min, &s.Max)
+			ho.Sectors = append(ho.Sectors, s)
+		default:
+			return ho, fmt.Errorf("ParseHoldOut: item: %v has unknown kind -- must be disp, region or sector", it)
+		}
+		if err != nil {
+			return ho, fmt.Errorf("ParseHoldOut: item: %v: %v", it, err)
+		}
+	}
+	return ho, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestHoldOut(t *testing.T) {
+	spec := "disp:4,0;region:0,0,3,3;sector:30,60"
+	ho, err := ParseHoldOut(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ho.String() != spec {
+		t.Errorf("round trip: %v != %v", ho.String(), spec)
+	}
+	tests := []struct {
+		start, disp image.Point
+		held        bool
+	}{
+		{image.Point{5, 5}, image.Point{4, 0}, true},   // disp
+		{image.Point{1, 2}, image.Point{0, 4}, true},   // region
+		{image.Point{5, 5}, image.Point{4, 4}, true},   // 45 deg sector
+		{image.Point{5, 5}, image.Point{0, -5}, false}, // 270 deg
+		{image.Point{3, 3}, image.Point{-4, 0}, false},
+	}
+	for _, tt := range tests {
+		if ho.Contains(tt.start, tt.disp) != tt.held {
+			t.Errorf("start: %v disp: %v held should be: %v", tt.start, tt.disp, tt.held)
+		}
+	}
+	if _, err := ParseHoldOut("bogus:1,2"); err == nil {
+		t.Errorf("expected error for unknown kind")
+	}
+
+	ev := ExEnv{}
+	ev.Config(9, 10)
+	ev.HoldOut = ho
+	ev.ExclHeld = true
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Trials != nil {
+		t.Errorf("Validate set %d Trials", len(ev.Trials))
+	}
+	for i := 0; i < 500; i++ {
+		ev.NewPoint()
+		if ev.IsHeld {
+			t.Fatalf("held-out trial generated: %v %v", ev.Point, ev.Point2)
+		}
+	}
+}
@@ -11,9 +11,12 @@ import (
 	"time"
 
 	"github.com/emer/emergent/env"
-	"github.com/emer/emergent/erand"
 	"github.com/emer/emergent/popcode"
+	"github.com/emer/etable/etable"
 	"github.com/emer/etable/etensor"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"myEnv/geom"
 )
 
 // ExEnv is an example environment, that sets a single input point in a 2D
@@ -21,9 +24,16 @@ import (
 // It can be used as a starting point for writing your own Env, without
 // having much existing code to rewrite.
 type ExEnv struct {
-	Nm         string `desc:"name of this environment"`
-	Dsc        string `desc:"description of this environment"`
-	Size       int    `desc:"size of each dimension in 2D input"`
+	Nm         string    `desc:"name of this environment"`
+	Dsc        string    `desc:"description of this environment"`
+	Size       int       `desc:"size of each dimension in 2D input"`
+	NItems     int       `desc:"number of items in the ordering, e.g., 4 for faces A, B, C, D -- the labels, popcode ranges and numbers of units are all derived from it in Config"`
+	Labels     []string  `desc:"label of each item, in order"`
+	Pairs      PairModes `desc:"which pairs of items are presented -- e.g., AdjacentPairs for training transitive inference, and AllPairs for testing it"`
+	Enum       bool      `desc:"if true, Step goes through every pair of PairSet in order, instead of sampling them at random -- Trial.Max is set to the number of pairs"`
+	PairSet    [][2]int  `view:"-" desc:"indexes of the items of every ordered pair presented under Pairs, set in Init"`
+	Faces      FaceCodes `desc:"identity codes of the faces on Face1, Face2 -- one-hot unless On"`
+	TwoD       bool      `desc:"if true, each item has a position on the Size x Size grid, encoded on 2D Input1, Input2 states, and the relation between two items is their displacement, as Distance and Angle targets, while SymDist and AdjacentPairs still follow the ordering of the items -- otherwise items are in order on a line, and the relation is the signed Distance between them"`
 	MinDist    float32
 	MaxDist    float32
 	MinInp     float32
@@ -31,18 +41,28 @@ type ExEnv struct {
 	NDistUnits int
 	NInpUnits  int
 	NFaceUnits int
+	NAngUnits  int
 	DistPop    popcode.OneD `desc:"population encoding of distance value"`
 	Input1Pop  popcode.OneD
 	Input2Pop  popcode.OneD
+	InputPop   popcode.TwoD `desc:"in TwoD, population encoding of the item positions on Input1, Input2"`
+	AnglePop   popcode.Ring `desc:"in TwoD, population encoding of the angle of the displacement"`
 	Face1      etensor.Float32
 	Face2      etensor.Float32
+	FacePats   *etable.Table    `view:"no-inline" desc:"identity code of each item, generated by Faces for the current run in Init"`
+	FaceSims   *etensor.Float32 `view:"no-inline" desc:"cosine similarity between the identity codes of every pair of items"`
 	Input1     etensor.Float32
 	Input2     etensor.Float32
 	Distance   etensor.Float32
+	Angle      etensor.Float32 `desc:"in TwoD, angle of the displacement from the first item to the second, in degrees counter-clockwise from the X axis"`
 	DistVal    float32
+	AngVal     float32
+	PosSeed    int64        `desc:"in TwoD, seed of the random placement of the items on the grid -- TrainEnv and TestEnv must have the same seed to share the same space"`
+	Positions  []mat32.Vec2 `desc:"in TwoD, X, Y grid position of each item, in order, set in Config"`
+	Pos1       mat32.Vec2   `desc:"in TwoD, grid position of the first item"`
+	Pos2       mat32.Vec2   `desc:"in TwoD, grid position of the second item"`
 	Inp1Val    float32
 	Inp2Val    float32
-	//HipTable   map[string]*etensor.Float32
 	Face1Val   string
 	Face2Val   string
 	Run        env.Ctr `view:"inline" desc:"current run of model as provided during Init"`
@@ -50,24 +70,52 @@ type ExEnv struct {
 	Trial      env.Ctr `view:"inline" desc:"trial increments over input states -- could add Event as a lower level"`
 }
 
+// PairModes are the policies for selecting the pairs of items presented on each trial
+type PairModes int32
+
+//go:generate stringer -type=PairModes
+
+var KiT_PairModes = kit.Enums.AddEnum(PairModesN, kit.NotBitFlag, nil)
+
+func (ev PairModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *PairModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// The pair modes
+const (
+	// AllPairs presents every ordered pair of different items
+	AllPairs PairModes = iota
+
+	// AdjacentPairs presents only the pairs of neighboring items in the
+	// ordering, at symbolic distance 1, in both orders -- the premise pairs
+	// of transitive inference
+	AdjacentPairs
+
+	PairModesN
+)
+
 func (ev *ExEnv) Name() string { return ev.Nm }
 func (ev *ExEnv) Desc() string { return ev.Dsc }
 
 // Config sets the size, number of trials to run per epoch, and configures the states
 func (ev *ExEnv) Config(sz int, ntrls int) {
 	ev.Size = sz
-	ev.MaxDist = float32(4)
-	ev.MinDist = float32(-4) // float32(-1*sz - 2)
-	ev.NDistUnits = 16
+	if ev.NItems == 0 {
+		ev.NItems = 4
+	}
+	n := ev.NItems
+	ev.Labels = ItemLabels(n)
+	ev.MaxDist = float32(n)
+	ev.MinDist = float32(-n) // float32(-1*sz - 2)
+	ev.NDistUnits = 4 * n
 	ev.DistPop.Defaults()
 	ev.DistPop.Min = ev.MinDist
 	ev.DistPop.Max = ev.MaxDist // + 2
 
-	ev.NFaceUnits = 4
+	ev.NFaceUnits = ev.Faces.NUnits(n)
 
-	ev.MaxInp = float32(3) // float32(sz)
-	ev.MinInp = -2
-	ev.NInpUnits = 10
+	ev.MaxInp = float32(n - 1) // float32(sz)
+	ev.MinInp = -float32(n) / 2
+	ev.NInpUnits = n * 5 / 2
 	ev.Input1Pop.Defaults()
 	ev.Input2Pop.Defaults()
 	ev.Input1Pop.Min = ev.MinInp
@@ -78,6 +126,23 @@ func (ev *ExEnv) Config(sz int, ntrls int) {
 	ev.Input2Pop.Sigma = 0.1
 	ev.DistPop.Sigma = 0.1
 
+	if ev.TwoD { // Euclidean distance and angle on the grid, as in phase one
+		ev.MaxDist = float32(sz-1) * math.Sqrt2
+		ev.MinDist = 0
+		ev.NDistUnits = 10
+		ev.DistPop.Min = -0.1 * ev.MaxDist
+		ev.DistPop.Max = 1.1 * ev.MaxDist
+		ev.NAngUnits = 24
+		ev.AnglePop.Defaults()
+		ev.AnglePop.Min = 0
+		ev.AnglePop.Max = 360
+		ev.InputPop.Defaults()
+		ev.InputPop.Min = mat32.NewVec2(-1, -1)
+		ev.InputPop.Max = mat32.NewVec2(float32(sz), float32(sz))
+		ev.InputPop.Sigma.Set(0.1, 0.1)
+		ev.Positions = ItemPositions(n, sz, ev.PosSeed)
+	}
+
 	currentTime := time.Now()
 	rand.Seed(int64(currentTime.Unix()))
 
@@ -86,21 +151,14 @@ func (ev *ExEnv) Config(sz int, ntrls int) {
 	ev.Distance.SetShape([]int{ev.NDistUnits}, nil, []string{"Distance"})
 	ev.Face1.SetShape([]int{ev.NFaceUnits}, nil, []string{"Face1"})
 	ev.Face2.SetShape([]int{ev.NFaceUnits}, nil, []string{"Face2"})
-	ev.Input1.SetShape([]int{ev.NInpUnits}, nil, []string{"Input1"})
-	ev.Input2.SetShape([]int{ev.NInpUnits}, nil, []string{"Input2"})
-
-	/*ev.HipTable = make(map[string]*etensor.Float32)
-	ev.HipTable["A"] = &etensor.Float32{}
-	ev.HipTable["B"] = &etensor.Float32{}
-	ev.HipTable["C"] = &etensor.Float32{}
-	ev.HipTable["D"] = &etensor.Float32{}
-
-	for _, tsr := range ev.HipTable {
-		tsr.SetShape([]int{ev.NInpUnits}, nil, []string{""})
-		for i := range tsr.Values {
-			tsr.Values[i] = float32(math.Max(erand.Gauss(0.5, -1), 0))
-		}
-	}*/
+	if ev.TwoD {
+		ev.Input1.SetShape([]int{sz, sz}, nil, []string{"Y", "X"})
+		ev.Input2.SetShape([]int{sz, sz}, nil, []string{"Y", "X"})
+		ev.Angle.SetShape([]int{ev.NAngUnits}, nil, []string{"Angle"})
+	} else {
+		ev.Input1.SetShape([]int{ev.NInpUnits}, nil, []string{"Input1"})
+		ev.Input2.SetShape([]int{ev.NInpUnits}, nil, []string{"Input2"})
+	}
 
 }
 
@@ -108,22 +166,107 @@ func (ev *ExEnv) Validate() error {
 	if ev.Size == 0 {
 		return fmt.Errorf("ExEnv: %v has size == 0 -- need to Config", ev.Nm)
 	}
+	if ev.NItems < 2 || ev.NItems > 26 {
+		return fmt.Errorf("ExEnv: %v has NItems: %d -- must be in 2..26", ev.Nm, ev.NItems)
+	}
+	if ev.Pairs < 0 || ev.Pairs >= PairModesN {
+		return fmt.Errorf("ExEnv: %v has invalid Pairs: %d", ev.Nm, ev.Pairs)
+	}
+	if err := ev.Faces.Validate(ev.NItems); err != nil {
+		return fmt.Errorf("ExEnv: %v: %v", ev.Nm, err)
+	}
+	if ev.TwoD && ev.NItems > ev.Size*ev.Size {
+		return fmt.Errorf("ExEnv: %v has NItems: %d -- more than the %d positions on the %d x %d grid", ev.Nm, ev.NItems, ev.Size*ev.Size, ev.Size, ev.Size)
+	}
 	return nil
 }
 
+// ItemPositions returns n different X, Y positions on the sz x sz grid,
+// placed at random with given seed
+func ItemPositions(n, sz int, seed int64) []mat32.Vec2 {
+	rnd := rand.New(rand.NewSource(seed))
+	pos := make([]mat32.Vec2, n)
+	for i, p := range rnd.Perm(sz * sz)[:n] {
+		pos[i] = mat32.NewVec2(float32(p%sz), float32(p/sz))
+	}
+	return pos
+}
+
+// PosError returns the distance in grid units between the position decoded
+// from given Input1 or Input2 activity pattern in TwoD, and position pos
+func (ev *ExEnv) PosError(pat etensor.Tensor, pos mat32.Vec2) (float32, error) {
+	p, err := ev.InputPop.Decode(pat)
+	if err != nil {
+		return 0, err
+	}
+	return p.DistTo(mat32.NewVec2(pos.Y, pos.X)), nil
+}
+
+// ItemPairs returns the indexes of every ordered pair of n items that is
+// presented under given mode, in order of the first item then the second --
+// adjacent pairs are neighbors in the ordering, by SymDist, also in TwoD
+func ItemPairs(n int, mode PairModes) [][2]int {
+	var prs [][2]int
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i == j || (mode == AdjacentPairs && SymDist(i, j) != 1) {
+				continue
+			}
+			prs = append(prs, [2]int{i, j})
+		}
+	}
+	return prs
+}
+
+// SymDist returns the symbolic distance between items i and j in the
+// ordering, i.e., the number of steps between them.  It is always ordinal,
+// also in TwoD: the ordering of the items is the hierarchy that transitive
+// inference is about, while their grid positions are random and unrelated
+// to it -- the Distance target in TwoD is the Euclidean distance on the grid.
+func SymDist(i, j int) int {
+	if i > j {
+		return i - j
+	}
+	return j - i
+}
+
+// HasPair returns true if the ordered pair of items i, j is in the PairSet
+func (ev *ExEnv) HasPair(i, j int) bool {
+	for _, pr := range ev.PairSet {
+		if pr == [2]int{i, j} {
+			return true
+		}
+	}
+	return false
+}
+
+// ItemLabels returns the labels of n items in order: A, B, C...
+func ItemLabels(n int) []string {
+	lbls := make([]string, n)
+	for i := range lbls {
+		lbls[i] = string(rune('A' + i))
+	}
+	return lbls
+}
+
 func (ev *ExEnv) Counters() []env.TimeScales {
 	return []env.TimeScales{env.Run, env.Epoch, env.Trial}
 }
 
+// States returns the shapes of the states, which are those of the tensors,
+// and match the Input and Target layers of the network -- see envcheck.Layers
 func (ev *ExEnv) States() env.Elements {
 	els := env.Elements{
 		// {"X", []int{ev.Size}, []string{"X"}},
 		// {"Y", []int{ev.Size}, []string{"Y"}},
-		{"Distance", []int{ev.Size}, []string{"Distance"}},
-		{"Face 1", []int{ev.Size}, []string{"Face 1"}},
-		{"Face 2", []int{ev.Size}, []string{"Face 2"}},
-		{"Input 1", []int{ev.Size}, []string{"Input 1"}},
-		{"Input 2", []int{ev.Size}, []string{"Input 2"}},
+		{"Distance", ev.Distance.Shapes(), ev.Distance.DimNames()},
+		{"Face1", ev.Face1.Shapes(), ev.Face1.DimNames()},
+		{"Face2", ev.Face2.Shapes(), ev.Face2.DimNames()},
+		{"Input1", ev.Input1.Shapes(), ev.Input1.DimNames()},
+		{"Input2", ev.Input2.Shapes(), ev.Input2.DimNames()},
+	}
+	if ev.TwoD {
+		els = append(els, env.Element{"Angle", ev.Angle.Shapes(), ev.Angle.DimNames()})
 	}
 	return els
 }
@@ -132,13 +275,18 @@ func (ev *ExEnv) State(element string) etensor.Tensor {
 	switch element {
 	case "Distance":
 		return &ev.Distance
-	case "Input 1":
+	case "Angle":
+		if !ev.TwoD {
+			return nil
+		}
+		return &ev.Angle
+	case "Input1":
 		return &ev.Input1
-	case "Input 2":
+	case "Input2":
 		return &ev.Input2
-	case "Face 1":
+	case "Face1":
 		return &ev.Face1
-	case "Face 2":
+	case "Face2":
 		return &ev.Face2
 	}
 	return nil
@@ -174,57 +322,65 @@ func (ev *ExEnv) Init(run int) {
 	ev.Trial.Init()
 	ev.Run.Cur = run
 	ev.Trial.Cur = -1 // init state -- key so that first Step() = 0
+	ev.PairSet = ItemPairs(ev.NItems, ev.Pairs)
+	ev.FacePats = ev.Faces.Gen(ev.Labels, run)
+	ev.FaceSims = FaceSims(ev.FacePats)
+	if ev.Enum {
+		ev.Trial.Max = len(ev.PairSet)
+	}
 }
 
-// NewPoint generates a new point and sets state accordingly
+// NewPoint samples a new pair from the PairSet and sets state accordingly
 func (ev *ExEnv) NewPoint() {
-	input1 := rand.Intn(int(4)) //rand.Intn(int(ev.MaxInp))
-	input2 := input1
-	for {
-		input2 = rand.Intn(int(4))
-		if input2 != input1 {
-			break
-		}
-	}
+	pr := ev.PairSet[rand.Intn(len(ev.PairSet))]
+	ev.SetPair(pr[0], pr[1])
+}
+
+// SetPair sets the states for the pair of items with given indexes
+func (ev *ExEnv) SetPair(input1, input2 int) {
 	distance := input2 - input1
-	switch input1 {
-	case 0:
-		ev.Face1Val = "A"
-	case 1:
-		ev.Face1Val = "B"
-	case 2:
-		ev.Face1Val = "C"
-	case 3:
-		ev.Face1Val = "D"
-	}
-	switch input2 {
-	case 0:
-		ev.Face2Val = "A"
-	case 1:
-		ev.Face2Val = "B"
-	case 2:
-		ev.Face2Val = "C"
-	case 3:
-		ev.Face2Val = "D"
-	}
-
-	ev.Face1.SetZeros()
-	ev.Face1.SetFloat([]int{input1}, 1)
-	ev.Face2.SetZeros()
-	ev.Face2.SetFloat([]int{input2}, 1)
+	ev.Face1Val = ev.Labels[input1]
+	ev.Face2Val = ev.Labels[input2]
+
+	ev.Face1.CopyFrom(ev.FacePats.CellTensor("Face", input1))
+	ev.Face2.CopyFrom(ev.FacePats.CellTensor("Face", input2))
 
+	ev.Inp1Val = float32(input1)
+	ev.Inp2Val = float32(input2)
+	if ev.TwoD {
+		ev.SetPositions(ev.Positions[input1], ev.Positions[input2])
+		return
+	}
 	ev.Input1Pop.Encode(&ev.Input1.Values, float32(input1), int(ev.NInpUnits), false)
 	ev.Input2Pop.Encode(&ev.Input2.Values, float32(input2), int(ev.NInpUnits), false)
 	ev.DistPop.Encode(&ev.Distance.Values, float32(distance), ev.NDistUnits, false)
 	ev.DistVal = float32(distance)
-	ev.Inp1Val = float32(input1)
-	ev.Inp2Val = float32(input2)
+}
+
+// SetPositions sets the TwoD states for items at given grid positions: the
+// positions on Input1, Input2, and the length and angle of the displacement
+// from the first to the second on Distance, Angle
+func (ev *ExEnv) SetPositions(pos1, pos2 mat32.Vec2) {
+	ev.Pos1 = pos1
+	ev.Pos2 = pos2
+	ev.DistVal, ev.AngVal = geom.Polar(pos2.Sub(pos1))
+	ev.Input1.SetZeros()
+	ev.InputPop.Encode(&ev.Input1, mat32.NewVec2(pos1.Y, pos1.X), false)
+	ev.Input2.SetZeros()
+	ev.InputPop.Encode(&ev.Input2, mat32.NewVec2(pos2.Y, pos2.X), false)
+	ev.DistPop.Encode(&ev.Distance.Values, ev.DistVal, ev.NDistUnits, false)
+	ev.AnglePop.Encode(&ev.Angle.Values, ev.AngVal, ev.NAngUnits)
 }
 
 // Step is called to advance the environment state
 func (ev *ExEnv) Step() bool {
 	ev.Epoch.Same() // good idea to just reset all non-inner-most counters at start
-	ev.NewPoint()
+	if ev.Enum {
+		pr := ev.PairSet[(ev.Trial.Cur+1)%len(ev.PairSet)] // Trial is incremented below
+		ev.SetPair(pr[0], pr[1])
+	} else {
+		ev.NewPoint()
+	}
 	if ev.Trial.Incr() { // true if wraps around Max back to 0
 		ev.Epoch.Incr()
 	}
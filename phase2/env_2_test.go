@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/goki/mat32"
+)
+
+func TestNItems(t *testing.T) {
+	for _, n := range []int{4, 5, 7, 10} {
+		ev := ExEnv{NItems: n}
+		ev.Config(5, 10)
+		if err := ev.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		ev.Init(0)
+		if len(ev.Labels) != n || ev.Labels[n-1] != string(rune('A'+n-1)) {
+			t.Errorf("%d items: labels %v", n, ev.Labels)
+		}
+		if ev.NFaceUnits != n || ev.Face1.Len() != n || ev.Input1.Len() != ev.NInpUnits {
+			t.Errorf("%d items: %d face units, Face1 %d, Input1 %d of %d units", n, ev.NFaceUnits, ev.Face1.Len(), ev.Input1.Len(), ev.NInpUnits)
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if i == j {
+					continue
+				}
+				ev.SetPair(i, j)
+				if ev.Face1.Values[i] != 1 || ev.Face2.Values[j] != 1 {
+					t.Errorf("%d items: pair %d, %d: faces %v %v", n, i, j, ev.Face1.Values, ev.Face2.Values)
+				}
+				if ev.DistVal != float32(j-i) || ev.DistVal <= ev.MinDist || ev.DistVal >= ev.MaxDist {
+					t.Errorf("%d items: pair %d, %d: distance %g out of (%g, %g)", n, i, j, ev.DistVal, ev.MinDist, ev.MaxDist)
+				}
+				if d := ev.DistPop.Decode(ev.Distance.Values); mat32.Abs(d-ev.DistVal) > 0.5 {
+					t.Errorf("%d items: pair %d, %d: distance %g decoded as %g", n, i, j, ev.DistVal, d)
+				}
+				if d := ev.Input1Pop.Decode(ev.Input1.Values); mat32.Abs(d-float32(i)) > 0.5 {
+					t.Errorf("%d items: item %d decoded from Input1 as %g", n, i, d)
+				}
+			}
+		}
+	}
+	ev := ExEnv{NItems: 27}
+	ev.Config(5, 10)
+	if err := ev.Validate(); err == nil {
+		t.Error("no error for 27 items")
+	}
+}
+
+func TestItemPairs(t *testing.T) {
+	n := 5
+	all := ItemPairs(n, AllPairs)
+	if len(all) != n*(n-1) {
+		t.Errorf("%d all pairs, want %d", len(all), n*(n-1))
+	}
+	adj := ItemPairs(n, AdjacentPairs)
+	if len(adj) != 2*(n-1) {
+		t.Errorf("%d adjacent pairs, want %d", len(adj), 2*(n-1))
+	}
+	for _, pr := range adj {
+		if SymDist(pr[0], pr[1]) != 1 {
+			t.Errorf("adjacent pair %v at symbolic distance %d", pr, SymDist(pr[0], pr[1]))
+		}
+	}
+	if SymDist(4, 1) != 3 || SymDist(1, 4) != 3 || SymDist(2, 2) != 0 {
+		t.Error("SymDist is not the number of steps between the items")
+	}
+
+	ev := ExEnv{NItems: n, Pairs: AdjacentPairs, Enum: true}
+	ev.Config(5, 10)
+	ev.Init(0)
+	if !ev.HasPair(1, 2) || !ev.HasPair(2, 1) || ev.HasPair(1, 3) || ev.HasPair(2, 2) {
+		t.Error("HasPair does not follow the adjacent pairs")
+	}
+	if ev.Trial.Max != len(adj) {
+		t.Errorf("Enum: Trial.Max %d, want %d", ev.Trial.Max, len(adj))
+	}
+	for i := 0; i < 2*len(adj); i++ {
+		ev.Step()
+		i1, i2 := int(ev.Inp1Val), int(ev.Inp2Val)
+		if pr := adj[i%len(adj)]; [2]int{i1, i2} != pr {
+			t.Errorf("step %d: pair %d, %d, want %v", i, i1, i2, pr)
+		}
+	}
+
+	ev.Pairs = AllPairs
+	ev.Enum = false
+	ev.Init(0)
+	for i := 0; i < 100; i++ {
+		ev.Step()
+		if i1, i2 := int(ev.Inp1Val), int(ev.Inp2Val); i1 == i2 || !ev.HasPair(i1, i2) {
+			t.Errorf("sampled pair %d, %d not in the PairSet", i1, i2)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+	"github.com/goki/mat32"
+)
+
+// FaceCodes configures the identity codes of the faces on the Face1, Face2
+// states: one-hot over the items, or if On, random sparse binary patterns.
+// The overlap of the codes is graded over the order of the items: the
+// active units of each code are a window sliding along a random order of
+// the units, so that adjacent items, e.g., A and B, share the Overlap
+// fraction of their active units, and items further apart share less,
+// e.g., A and D, falling off linearly with the distance between them in
+// the order until they share none.  FaceSims thus differs between pairs.
+type FaceCodes struct {
+	On      bool    `desc:"use distributed codes -- otherwise faces are one-hot over the items"`
+	Size    int     `desc:"number of units in each code"`
+	PctAct  float32 `min:"0" max:"1" desc:"fraction of the units that are active in each code"`
+	Overlap float32 `min:"0" max:"1" desc:"fraction of the active units of each code that are shared with the codes of the adjacent items -- items k apart share 1 - k * (1 - Overlap) of them, down to none"`
+	Seed    int64   `desc:"seed of the random codes, combined with the run, so that TrainEnv and TestEnv generate the same codes in each run"`
+}
+
+// Defaults sets the size and activity of the codes
+func (fc *FaceCodes) Defaults() {
+	fc.Size = 40
+	fc.PctAct = 0.2
+}
+
+// NUnits returns the number of units of the codes of n items
+func (fc *FaceCodes) NUnits(n int) int {
+	if !fc.On {
+		return n
+	}
+	return fc.Size
+}
+
+// NActive returns the number of active units in each code -- at least 1
+func (fc *FaceCodes) NActive() int {
+	na := int(mat32.Round(fc.PctAct * float32(fc.Size)))
+	if na < 1 {
+		na = 1
+	}
+	return na
+}
+
+// NShared returns the number of active units that the codes of adjacent
+// items share
+func (fc *FaceCodes) NShared() int {
+	return int(mat32.Round(fc.Overlap * float32(fc.NActive())))
+}
+
+// NStep returns the number of units that the window of active units moves
+// from each code to the next -- those not shared by adjacent codes
+func (fc *FaceCodes) NStep() int {
+	return fc.NActive() - fc.NShared()
+}
+
+// Validate returns an error if the codes of n items do not fit in Size units
+func (fc *FaceCodes) Validate(n int) error {
+	if !fc.On {
+		return nil
+	}
+	if fc.Size <= 0 || fc.PctAct <= 0 || fc.PctAct > 1 {
+		return fmt.Errorf("FaceCodes: Size: %d and PctAct: %g must be positive, with PctAct at most 1", fc.Size, fc.PctAct)
+	}
+	if fc.Overlap < 0 || fc.Overlap > 1 {
+		return fmt.Errorf("FaceCodes: Overlap: %g is out of range 0..1", fc.Overlap)
+	}
+	na, ns := fc.NActive(), fc.NShared()
+	if nu := na + (n-1)*fc.NStep(); nu > fc.Size {
+		return fmt.Errorf("FaceCodes: %d codes of %d active units, %d of them shared by adjacent codes, need %d units, more than Size: %d", n, na, ns, nu, fc.Size)
+	}
+	return nil
+}
+
+// Gen returns the table of the codes of the items with given labels for
+// given run, with the label of each item in the Name column, and its code
+// in the Face column
+func (fc *FaceCodes) Gen(lbls []string, run int) *etable.Table {
+	n := len(lbls)
+	nu := fc.NUnits(n)
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "FacePats")
+	dt.SetMetaData("desc", "identity codes of the faces")
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Face", etensor.FLOAT32, []int{nu}, []string{"Face"}},
+	}, n)
+	pats := dt.ColByName("Face").(*etensor.Float32)
+	for i, lbl := range lbls {
+		dt.SetCellString("Name", i, lbl)
+	}
+	if !fc.On {
+		for i := range lbls {
+			pats.Values[i*nu+i] = 1
+		}
+		return dt
+	}
+	rnd := rand.New(rand.NewSource(fc.Seed + int64(run)))
+	perm := rnd.Perm(nu)
+	na, st := fc.NActive(), fc.NStep()
+	for i := range lbls {
+		for _, u := range perm[i*st : i*st+na] {
+			pats.Values[i*nu+u] = 1
+		}
+	}
+	return dt
+}
+
+// FaceSims returns the matrix of the cosine similarities between every
+// pair of the codes in given table, as made by FaceCodes.Gen
+func FaceSims(dt *etable.Table) *etensor.Float32 {
+	n := dt.Rows
+	sims := etensor.NewFloat32([]int{n, n}, nil, []string{"Face", "Face"})
+	for i := 0; i < n; i++ {
+		pi := dt.CellTensor("Face", i).(*etensor.Float32)
+		for j := 0; j < n; j++ {
+			pj := dt.CellTensor("Face", j).(*etensor.Float32)
+			sims.Set([]int{i, j}, metric.Cosine32(pi.Values, pj.Values))
+		}
+	}
+	return sims
+}
+
+// MeanOffDiag returns the mean of the values of given square matrix that
+// are not on the diagonal, e.g., the mean similarity of different faces
+func MeanOffDiag(mat *etensor.Float32) float32 {
+	n := mat.Dim(0)
+	if n < 2 {
+		return 0
+	}
+	sum := float32(0)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j {
+				sum += mat.Value([]int{i, j})
+			}
+		}
+	}
+	return sum / float32(n*(n-1))
+}
+
+// String returns the spec of the codes, as parsed by ParseFaceCodes --
+// empty for one-hot codes
+func (fc *FaceCodes) String() string {
+	if !fc.On {
+		return ""
+	}
+	return fmt.Sprintf("size:%d;act:%g;overlap:%g;seed:%d", fc.Size, fc.PctAct, fc.Overlap, fc.Seed)
+}
+
+// ParseFaceCodes returns the FaceCodes for given spec, which is a
+// semicolon-separated list of kind:value items, e.g., size:40;act:0.2;overlap:0.25
+// -- kinds are size, act, overlap and seed, and any of them turns the
+// distributed codes On -- an empty spec is for one-hot codes
+func ParseFaceCodes(spec string) (FaceCodes, error) {
+	fc := FaceCodes{}
+	fc.Defaults()
+	for _, it := range strings.Split(spec, ";") {
+		it = strings.TrimSpace(it)
+		if it == "" {
+			continue
+		}
+		kv := strings.SplitN(it, ":", 2)
+		if len(kv) != 2 {
+			return fc, fmt.Errorf("ParseFaceCodes: item: %v is not of the form kind:value", it)
+		}
+		var err error
+		switch kv[0] {
+		case "size":
+			_, err = fmt.Sscanf(kv[1], "%d", &fc.Size)
+		case "act":
+			_, err = fmt.Sscanf(kv[1], "%g", &fc.PctAct)
+		case "overlap":
+			_, err = fmt.Sscanf(kv[1], "%g", &fc.Overlap)
+		case "seed":
+			_, err = fmt.Sscanf(kv[1], "%d", &fc.Seed)
+		default:
+			return fc, fmt.Errorf("ParseFaceCodes: item: %v has unknown kind -- must be size, act, overlap or seed", it)
+		}
+		if err != nil {
+			return fc, fmt.Errorf("ParseFaceCodes: item: %v: %v", it, err)
+		}
+		fc.On = true
+	}
+	return fc, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/emer/etable/etensor"
+	"github.com/goki/mat32"
+)
+
+func TestParseFaceCodes(t *testing.T) {
+	fc, err := ParseFaceCodes("size:40;act:0.2;overlap:0.25;seed:3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fc.On || fc.Size != 40 || fc.PctAct != 0.2 || fc.Overlap != 0.25 || fc.Seed != 3 {
+		t.Errorf("parsed %+v", fc)
+	}
+	if fc2, err := ParseFaceCodes(fc.String()); err != nil || fc2 != fc {
+		t.Errorf("round trip of %v: %+v, %v", fc.String(), fc2, err)
+	}
+	if fc, err := ParseFaceCodes(""); err != nil || fc.On || fc.String() != "" {
+		t.Errorf("empty spec: %+v, %v -- want one-hot", fc, err)
+	}
+	for _, spec := range []string{"size", "color:red", "act:x"} {
+		if _, err := ParseFaceCodes(spec); err == nil {
+			t.Errorf("%v: no error", spec)
+		}
+	}
+}
+
+func TestFaceCodesValidate(t *testing.T) {
+	fc := FaceCodes{On: true, Size: 20, PctAct: 0.2, Overlap: 0.5}
+	if err := fc.Validate(4); err != nil { // 4 active + 3 steps of 2
+		t.Error(err)
+	}
+	if err := fc.Validate(10); err == nil { // 4 active + 9 steps of 2
+		t.Error("no error for 10 codes in 20 units")
+	}
+}
+
+func TestFaceSims(t *testing.T) {
+	lbls := []string{"A", "B", "C", "D"}
+	fc := FaceCodes{}
+	fc.Defaults()
+	sims := FaceSims(fc.Gen(lbls, 0))
+	if ms := MeanOffDiag(sims); ms != 0 {
+		t.Errorf("one-hot codes: mean similarity %g, want 0", ms)
+	}
+
+	fc.On = true
+	fc.Overlap = 0.75 // 6 of the 8 active units shared by adjacent codes
+	dt := fc.Gen(lbls, 0)
+	for i := range lbls {
+		pat := dt.CellTensor("Face", i).(*etensor.Float32)
+		na := 0
+		for _, v := range pat.Values {
+			if v == 1 {
+				na++
+			}
+		}
+		if na != fc.NActive() {
+			t.Errorf("code %d: %d active units, want %d", i, na, fc.NActive())
+		}
+	}
+	sims = FaceSims(dt)
+	for i := range lbls {
+		for j := range lbls {
+			k := i - j
+			if k < 0 {
+				k = -k
+			}
+			want := mat32.Max(0, 1-float32(k)*(1-fc.Overlap)) // 1, .75, .5, .25
+			if s := sims.Value([]int{i, j}); mat32.Abs(s-want) > 1e-6 {
+				t.Errorf("codes %d, %d: similarity %g, want %g", i, j, s, want)
+			}
+		}
+	}
+	if ab, ad := sims.Value([]int{0, 1}), sims.Value([]int{0, 3}); ab <= ad {
+		t.Errorf("A and B similarity %g is not more than A and D %g", ab, ad)
+	}
+
+	again := fc.Gen(lbls, 0).ColByName("Face").(*etensor.Float32)
+	other := fc.Gen(lbls, 1).ColByName("Face").(*etensor.Float32)
+	same, diff := true, false
+	for i, v := range dt.ColByName("Face").(*etensor.Float32).Values {
+		same = same && again.Values[i] == v
+		diff = diff || other.Values[i] != v
+	}
+	if !same || !diff {
+		t.Errorf("codes must be the same for the same run (%v), and differ across runs (%v)", same, diff)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+)
+
+// HipMem is a simple episodic memory, standing in for the hippocampus: it
+// records the settled activity of the Input1, Input2 layers for each face,
+// by its label, and can retrieve it in place of the Input states of the env.
+// With the original Input state of each face, it measures the fidelity of
+// the recorded representations.
+type HipMem struct {
+	Record   bool                        `desc:"record the settled Input1, Input2 activity for the faces of every trial of the face phase of training"`
+	Retrieve bool                        `desc:"ApplyInputs applies the recorded activity for the faces of the trial to Input1, Input2, instead of the Input states of the env -- faces with no record get the env states"`
+	Pats     map[string]*etensor.Float32 `view:"no-inline" desc:"recorded Input activity for each face label"`
+	Origs    map[string]*etensor.Float32 `view:"-" desc:"Input state of the env for each face label, when its activity was recorded -- the original code that the fidelity is relative to"`
+}
+
+// Init forgets all the recorded patterns
+func (hm *HipMem) Init() {
+	hm.Pats = make(map[string]*etensor.Float32)
+	hm.Origs = make(map[string]*etensor.Float32)
+}
+
+// Store records copies of given activity of an Input layer for the face with
+// given label, and of the original Input state of the face in the env
+func (hm *HipMem) Store(lbl string, act, orig *etensor.Float32) {
+	if hm.Pats == nil {
+		hm.Init()
+	}
+	hm.Pats[lbl] = act.Clone().(*etensor.Float32)
+	hm.Origs[lbl] = orig.Clone().(*etensor.Float32)
+}
+
+// Recall returns the recorded activity for the face with given label --
+// nil if there is none
+func (hm *HipMem) Recall(lbl string) *etensor.Float32 {
+	return hm.Pats[lbl]
+}
+
+// Has returns true if there is a recorded activity for the face with given label
+func (hm *HipMem) Has(lbl string) bool {
+	_, has := hm.Pats[lbl]
+	return has
+}
+
+// Fidelity returns the cosine between the recorded activity for the face
+// with given label and its original Input state -- 0 if there is none
+func (hm *HipMem) Fidelity(lbl string) float32 {
+	pat, has := hm.Pats[lbl]
+	if !has {
+		return 0
+	}
+	return metric.Cosine32(pat.Values, hm.Origs[lbl].Values)
+}
+
+// MeanFidelity returns the mean Fidelity over the recorded faces -- 0 if none
+func (hm *HipMem) MeanFidelity() float32 {
+	if len(hm.Pats) == 0 {
+		return 0
+	}
+	sum := float32(0)
+	for lbl := range hm.Pats {
+		sum += hm.Fidelity(lbl)
+	}
+	return sum / float32(len(hm.Pats))
+}
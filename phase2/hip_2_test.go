@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/emer/etable/etensor"
+	"github.com/goki/mat32"
+)
+
+func TestHipMem(t *testing.T) {
+	hm := HipMem{}
+	if hm.Recall("A") != nil || hm.Has("A") || hm.MeanFidelity() != 0 {
+		t.Error("empty memory recalls something")
+	}
+	orig := etensor.NewFloat32([]int{4}, nil, nil)
+	copy(orig.Values, []float32{1, 1, 0, 0})
+	act := etensor.NewFloat32([]int{4}, nil, nil)
+	copy(act.Values, []float32{1, 0, 0, 0})
+	hm.Store("A", act, orig)
+	hm.Store("B", orig, orig)
+
+	act.Values[1] = 1 // stored copies must not follow the layer
+	if pat := hm.Recall("A"); pat == nil || pat.Values[1] != 0 {
+		t.Errorf("recalled %v, want the activity at Store", pat)
+	}
+	if !hm.Has("B") || hm.Has("C") || hm.Recall("C") != nil {
+		t.Error("Has or Recall of a face with no record")
+	}
+	if f := hm.Fidelity("A"); mat32.Abs(f-mat32.Sqrt(0.5)) > 1e-6 {
+		t.Errorf("fidelity of A: %g, want %g", f, mat32.Sqrt(0.5))
+	}
+	if f := hm.Fidelity("B"); mat32.Abs(f-1) > 1e-6 {
+		t.Errorf("fidelity of B: %g, want 1", f)
+	}
+	if mf := hm.MeanFidelity(); mat32.Abs(mf-(1+mat32.Sqrt(0.5))/2) > 1e-6 {
+		t.Errorf("mean fidelity: %g", mf)
+	}
+
+	hm.Init()
+	if hm.Has("A") || hm.MeanFidelity() != 0 {
+		t.Error("Init did not forget the records")
+	}
+}
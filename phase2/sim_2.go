@@ -33,6 +33,8 @@ import (
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
 	"github.com/goki/mat32"
+	"myEnv/envcheck"
+	"myEnv/geom"
 )
 
 func main() {
@@ -88,6 +90,10 @@ var ParamSets = params.Sets{
 				Params: params.Params{
 					"Prjn.WtScale.Rel": "0.5",
 				}},
+			{Sel: "#AngleToCombined Hidden", Desc: "same as Distance, in TwoD",
+				Params: params.Params{
+					"Prjn.WtScale.Rel": "0.5",
+				}},
 			{Sel: ".Hidden", Desc: "Noise for hidden layers",
 				Params: params.Params{
 					"Layer.Act.Noise.Dist": "Gaussian",
@@ -137,6 +143,12 @@ var ParamSets = params.Sets{
 // for the fields which provide hints to how things should be displayed).
 type Sim struct {
 	Size         int               `desc:"size of each dim in 2D input"`
+	NItems       int               `desc:"number of items in the ordering of faces, e.g., 5, 7 or 10 -- the layer sizes follow it"`
+	TrainPairs   PairModes         `desc:"which pairs of items TrainEnv presents -- AdjacentPairs for transitive inference, or AllPairs as a control -- TestEnv always presents all of them"`
+	TwoD         bool              `desc:"if true, faces have positions in a 2D social space, on 2D Input layers, and the relation between two faces is decoded as Distance and Angle, as in phase one -- otherwise faces are in order on a line"`
+	FaceCodes    string            `desc:"spec of distributed face identity codes -- see ParseFaceCodes, e.g., size:40;act:0.2;overlap:0.25 -- empty for one-hot faces"`
+	FaceEpc      int               `desc:"after this epoch, training presents the faces instead of the Input positions -- the face phase, in which Hip records"`
+	Hip          HipMem            `desc:"episodic memory of the settled Input1, Input2 representations of the faces, standing in for the hippocampus"`
 	Net          *leabra.Network   `view:"no-inline" desc:"the network -- click to view / edit parameters for layers, prjns, etc"`
 	TrnEpcLog    *etable.Table     `view:"no-inline" desc:"training epoch-level log data"`
 	TstEpcLog    *etable.Table     `view:"no-inline" desc:"testing epoch-level log data"`
@@ -146,6 +158,7 @@ type Sim struct {
 	TstCycLog    *etable.Table     `view:"no-inline" desc:"testing cycle-level log data"`
 	RunLog       *etable.Table     `view:"no-inline" desc:"summary log of each run"`
 	RunStats     *etable.Table     `view:"no-inline" desc:"aggregate stats on all runs"`
+	HipLog       *etable.Table     `view:"no-inline" desc:"contents of Hip at the end of each training epoch, one row per face, with the fidelity to its original Input code"`
 	Params       params.Sets       `view:"no-inline" desc:"full collection of param sets"`
 	ParamSet     string            `desc:"which set of *additional* parameters to use -- always applies Base and optionaly this next if set"`
 	Tag          string            `desc:"extra tag string to add to any file names output from sim (e.g., weights files, log files, params for run)"`
@@ -178,12 +191,14 @@ type Sim struct {
 	EpcCosDiffInp1 float64
 	EpcCosDiffInp2 float64
 	EpcDistError   float64
+	EpcAngError    float64
 	EpcInp1Error   float64
 	EpcInp2Error   float64
 	EpcPerTrlMSec  float64 `inactive:"+" desc:"how long did the epoch take per trial in wall-clock milliseconds"`
 	FirstZero      int     `inactive:"+" desc:"epoch at when SSE first went to zero"`
 	NZero          int     `inactive:"+" desc:"number of epochs in a row with zero SSE"`
 	DistanceError  float64
+	AngleError     float64 `inactive:"+" desc:"in TwoD, error of the decoded Angle, in degrees / 360"`
 	Input1Error    float64
 	Input2Error    float64
 	Inp1Value      float64
@@ -198,6 +213,7 @@ type Sim struct {
 	SumCosDiffInp1 float64
 	SumCosDiffInp2 float64
 	SumDistError   float64
+	SumAngError    float64
 	SumInp1Error   float64
 	SumInp2Error   float64
 	Win            *gi.Window                  `view:"-" desc:"main GUI window"`
@@ -231,6 +247,10 @@ var TheSim Sim
 // New creates new blank elements and initializes defaults
 func (ss *Sim) New() {
 	ss.Size = 9
+	ss.NItems = 4
+	ss.TrainPairs = AdjacentPairs
+	ss.FaceEpc = 20
+	ss.Hip.Record = true
 	ss.Net = &leabra.Network{}
 	ss.TrnEpcLog = &etable.Table{}
 	ss.TstEpcLog = &etable.Table{}
@@ -238,6 +258,7 @@ func (ss *Sim) New() {
 	ss.TstCycLog = &etable.Table{}
 	ss.RunLog = &etable.Table{}
 	ss.RunStats = &etable.Table{}
+	ss.HipLog = &etable.Table{}
 	ss.Params = ParamSets
 	ss.RndSeed = 1
 	ss.ViewOn = true
@@ -255,11 +276,15 @@ func (ss *Sim) Config() {
 	//ss.ConfigPats()
 	ss.ConfigEnv()
 	ss.ConfigNet(ss.Net)
+	if err := ss.CheckEnvs(); err != nil {
+		log.Fatalln(err)
+	}
 	ss.ConfigTrnEpcLog(ss.TrnEpcLog)
 	ss.ConfigTstEpcLog(ss.TstEpcLog)
 	ss.ConfigTstTrlLog(ss.TstTrlLog)
 	ss.ConfigTstCycLog(ss.TstCycLog)
 	ss.ConfigRunLog(ss.RunLog)
+	ss.ConfigHipLog(ss.HipLog)
 }
 
 func (ss *Sim) ConfigEnv() {
@@ -271,16 +296,34 @@ func (ss *Sim) ConfigEnv() {
 		ss.NZeroStop = -1
 	}
 
+	faces, err := ParseFaceCodes(ss.FaceCodes)
+	if err != nil {
+		log.Println(err)
+	}
+
 	ss.TrainEnv.Nm = "TrainEnv"
 	ss.TrainEnv.Dsc = "training params and state"
+	ss.TrainEnv.NItems = ss.NItems
+	ss.TrainEnv.Pairs = ss.TrainPairs
+	ss.TrainEnv.TwoD = ss.TwoD
+	ss.TrainEnv.Faces = faces
 	ss.TrainEnv.Config(ss.Size, 100)
-	ss.TrainEnv.Validate()
+	if err := ss.TrainEnv.Validate(); err != nil {
+		log.Println(err)
+	}
 	ss.TrainEnv.Run.Max = ss.MaxRuns // note: we are not setting epoch max -- do that manually
 
 	ss.TestEnv.Nm = "TestEnv"
 	ss.TestEnv.Dsc = "testing params and state"
+	ss.TestEnv.NItems = ss.NItems
+	ss.TestEnv.Pairs = AllPairs
+	ss.TestEnv.TwoD = ss.TwoD // same PosSeed, so the same positions as TrainEnv
+	ss.TestEnv.Faces = faces  // same Seed, so the same codes as TrainEnv
+	ss.TestEnv.Enum = true    // each pair once per test epoch
 	ss.TestEnv.Config(ss.Size, 50)
-	ss.TestEnv.Validate()
+	if err := ss.TestEnv.Validate(); err != nil {
+		log.Println(err)
+	}
 
 	// note: to create a train / test split of pats, do this:
 	// all := etable.NewIdxView(ss.Pats)
@@ -297,8 +340,12 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	face1 := net.AddLayer2D("Face1", 1, ss.TrainEnv.NFaceUnits, emer.Input)
 	face2 := net.AddLayer2D("Face2", 1, ss.TrainEnv.NFaceUnits, emer.Input)
 
-	inp1 := net.AddLayer2D("Input1", 1, ss.TrainEnv.NInpUnits, emer.Input)
-	inp2 := net.AddLayer2D("Input2", 1, ss.TrainEnv.NInpUnits, emer.Input)
+	inpy, inpx := 1, ss.TrainEnv.NInpUnits
+	if ss.TrainEnv.TwoD { // positions on the grid
+		inpy, inpx = ss.TrainEnv.Size, ss.TrainEnv.Size
+	}
+	inp1 := net.AddLayer2D("Input1", inpy, inpx, emer.Input)
+	inp2 := net.AddLayer2D("Input2", inpy, inpx, emer.Input)
 	//hid1 := net.AddLayer2D("Hidden1", 15, 15, emer.Hidden)
 	//hid2 := net.AddLayer2D("Hidden2", 15, 15, emer.Hidden)
 	combhid := net.AddLayer2D("Combined Hidden", 9, 9, emer.Hidden)
@@ -351,6 +398,10 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	//net.BidirConnectLayers(combhid, hid2, full)
 	///net.BidirConnectLayers(hid2, dist, full)
 	net.BidirConnectLayers(combhid, dist, full)
+	if ss.TrainEnv.TwoD {
+		ang := net.AddLayer2D("Angle", 1, ss.TrainEnv.NAngUnits, emer.Target)
+		net.BidirConnectLayers(combhid, ang, full)
+	}
 
 	// note: if you wanted to change a layer type from e.g., Target to Compare, do this:
 	// out.SetType(emer.Compare)
@@ -376,12 +427,44 @@ func (ss *Sim) Init() {
 	rand.Seed(ss.RndSeed)
 	ss.ConfigEnv() // re-config env just in case a different set of patterns was
 	// selected or patterns have been modified etc
+	if err := ss.CheckEnvs(); err != nil { // a new NItems, TwoD or FaceCodes changed the shapes
+		ss.RebuildNet()
+		if err := ss.CheckEnvs(); err != nil {
+			log.Fatalln(err) // same as Config -- never train a network that does not match the envs
+		}
+	}
+	ss.ConfigEnvLogs()
 	ss.StopNow = false
 	ss.SetParams("", ss.LogSetParams) // all sheets
 	ss.NewRun()
 	ss.UpdateView(true)
 }
 
+// RebuildNet makes a new network, sized from the current envs -- for when a
+// new NItems, TwoD or FaceCodes change the shapes of the layers.  The logs
+// with layer columns, their plots and the NetView follow the new network.
+func (ss *Sim) RebuildNet() {
+	ss.Net = &leabra.Network{}
+	ss.ConfigNet(ss.Net)
+	ss.ConfigTstTrlLog(ss.TstTrlLog)
+	ss.ConfigHipLog(ss.HipLog)
+	if ss.TstTrlPlot != nil {
+		ss.ConfigTstTrlPlot(ss.TstTrlPlot, ss.TstTrlLog)
+	}
+	if ss.NetView != nil {
+		ss.NetView.SetNet(ss.Net)
+	}
+}
+
+// ConfigEnvLogs re-configures the logs whose columns depend on the envs,
+// and their plots -- the DistErr_ columns of TstEpcLog follow NItems
+func (ss *Sim) ConfigEnvLogs() {
+	ss.ConfigTstEpcLog(ss.TstEpcLog)
+	if ss.TstEpcPlot != nil {
+		ss.ConfigTstEpcPlot(ss.TstEpcPlot, ss.TstEpcLog)
+	}
+}
+
 // NewRndSeed gets a new random seed based on current time -- otherwise uses
 // the same random seed for every run
 func (ss *Sim) NewRndSeed() {
@@ -395,7 +478,7 @@ func (ss *Sim) Counters(train bool) string {
 	if train {
 		return fmt.Sprintf("Run:\t%d\tEpoch:\t%d\tTrial:\t%d\tCycle:\t%d\tName:\t%v\t\t\t", ss.TrainEnv.Run.Cur, ss.TrainEnv.Epoch.Cur, ss.TrainEnv.Trial.Cur, ss.Time.Cycle, ss.TrainEnv.String(ss.InpTarg, ss.InpLayTarg, ss.TrainEnv.Face1Val, ss.TrainEnv.Face2Val))
 	} else {
-		return fmt.Sprintf("Run:\t%d\tEpoch:\t%d\tTrial:\t%d\tCycle:\t%d\tName:\t%v\t\t\t", ss.TrainEnv.Run.Cur, ss.TrainEnv.Epoch.Cur, ss.TestEnv.Trial.Cur, ss.Time.Cycle, ss.TestEnv.String(ss.InpTarg, ss.InpLayTarg, ss.TrainEnv.Face1Val, ss.TrainEnv.Face2Val))
+		return fmt.Sprintf("Run:\t%d\tEpoch:\t%d\tTrial:\t%d\tCycle:\t%d\tName:\t%v\t\t\t", ss.TrainEnv.Run.Cur, ss.TrainEnv.Epoch.Cur, ss.TestEnv.Trial.Cur, ss.Time.Cycle, ss.TestEnv.String(ss.InpTarg, ss.InpLayTarg, ss.TestEnv.Face1Val, ss.TestEnv.Face2Val))
 	}
 }
 
@@ -477,78 +560,16 @@ func (ss *Sim) AlphaCyc(train bool) {
 	}
 }
 
-// Second version of AlphaCyc function for use in hippocampus
-/*func (ss *Sim) AlphaCycHip(train bool) {
-	// ss.Win.PollEvents() // this can be used instead of running in a separate goroutine
-	viewUpdt := ss.TrainUpdt
-	if !train {
-		viewUpdt = ss.TestUpdt
-	}
-
-	ss.Net.AlphaCycInit()
-	ss.Time.AlphaCycStart()
-	for qtr := 0; qtr < 4; qtr++ {
-		for cyc := 0; cyc < ss.Time.CycPerQtr; cyc++ {
-			ss.Net.Cycle(&ss.Time)
-			if !train {
-				ss.LogTstCyc(ss.TstCycLog, ss.Time.Cycle)
-			}
-			ss.Time.CycleInc()
-			if ss.ViewOn {
-				switch viewUpdt {
-				case leabra.Cycle:
-					if cyc != ss.Time.CycPerQtr-1 { // will be updated by quarter
-						ss.UpdateView(train)
-					}
-				case leabra.FastSpike:
-					if (cyc+1)%10 == 0 {
-						ss.UpdateView(train)
-					}
-				}
-			}
+// CheckEnvs checks that the States of all the envs match the layers of the
+// network -- see envcheck.Layers
+func (ss *Sim) CheckEnvs() error {
+	for _, en := range []env.Env{&ss.TrainEnv, &ss.TestEnv} {
+		if err := envcheck.Layers(ss.Net, en); err != nil {
+			return err
 		}
-		ss.Net.QuarterFinal(&ss.Time)
-		ss.Time.QuarterInc()
-		if ss.ViewOn {
-			switch {
-			case viewUpdt <= leabra.Quarter:
-				ss.UpdateView(train)
-			case viewUpdt == leabra.Phase:
-				if qtr >= 2 {
-					ss.UpdateView(train)
-				}
-			}
-		}
-		if qtr >= 2 {
-			inp1 := ss.Net.LayerByName("Input1").(leabra.LeabraLayer).AsLeabra()
-			inp2 := ss.Net.LayerByName("Input2").(leabra.LeabraLayer).AsLeabra()
-			dist := ss.Net.LayerByName("Distance").(leabra.LeabraLayer).AsLeabra()
-			inp1.SetType(emer.Compare)
-			inp2.SetType(emer.Compare)
-			dist.SetType(emer.Input)
-			ss.Net.InitExt()
-			ss.ApplyInputs(&ss.TrainEnv)
-		}
-	}
-	inp1 := ss.Net.LayerByName("Input1").(leabra.LeabraLayer).AsLeabra()
-	inp1tsr := ss.ValsTsr(inp1.Nm)
-	inp1.UnitValsTensor(inp1tsr, "Act")
-	inp2 := ss.Net.LayerByName("Input2").(leabra.LeabraLayer).AsLeabra()
-	inp2tsr := ss.ValsTsr(inp2.Nm)
-	inp2.UnitValsTensor(inp2tsr, "Act")
-	ss.TrainEnv.HipTable[ss.TrainEnv.Face1Val] = inp1tsr
-	ss.TrainEnv.HipTable[ss.TrainEnv.Face2Val] = inp2tsr
-
-	if train {
-		ss.Net.DWt()
-	}
-	if ss.ViewOn && viewUpdt == leabra.AlphaCycle {
-		ss.UpdateView(train)
 	}
-	if !train {
-		ss.TstCycPlot.GoUpdate() // make sure up-to-date at end
-	}
-}*/
+	return nil
+}
 
 // ApplyInputs applies input patterns from given environment.
 // It is good practice to have this be a separate method with appropriate
@@ -558,45 +579,71 @@ func (ss *Sim) ApplyInputs(en env.Env) {
 	ss.Net.InitExt() // clear any existing inputs -- not strictly necessary if always
 	// going to the same layers, but good practice and cheap anyway
 
-	lays := []string{"Distance", "Input1", "Input2"}
+	lays := []string{"Distance", "Angle", "Input1", "Input2"}
 	for _, lnm := range lays {
-		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		lly, ok := ss.Net.LayerByName(lnm).(leabra.LeabraLayer)
+		if !ok { // Angle is only in TwoD
+			continue
+		}
+		ly := lly.AsLeabra()
 		pats := en.State(ly.Nm)
+		if ev, ok := en.(*ExEnv); ok && ss.Hip.Retrieve && ly.Type() == emer.Input { // never replace a target
+			if hpat := ss.HipPat(ev, ly.Nm); hpat != nil {
+				pats = hpat
+			}
+		}
 		if pats != nil {
 			ly.ApplyExt(pats)
 		}
 	}
+}
 
-	/*ly := ss.Net.LayerByName("Input1").(leabra.LeabraLayer).AsLeabra()
-	ly2 := ss.Net.LayerByName("Input2").(leabra.LeabraLayer).AsLeabra()
-	if hip {
-		pats := ss.TrainEnv.HipTable[ss.TrainEnv.Face1Val]
-		ly.ApplyExt((etensor.Tensor(pats)))
-
-		// Input2 Face Pats
-		pats2 := ss.TrainEnv.HipTable[ss.TrainEnv.Face2Val]
-		ly2.ApplyExt((etensor.Tensor(pats2)))
-	} else {
-		pats := en.State(ly.Nm)
-		if pats != nil {
-			ly.ApplyExt(pats)
-		}
+// HipPat returns the pattern recorded in Hip for the face of the current
+// trial of given env that goes with the Input1 or Input2 layer of given name
+// -- nil for other layers or if the face has no record.  Only for layers of
+// type Input: when InpTarg makes Input1 or Input2 a target, it must get the
+// state of the env.
+func (ss *Sim) HipPat(ev *ExEnv, lnm string) etensor.Tensor {
+	var lbl string
+	switch lnm {
+	case "Input1":
+		lbl = ev.Face1Val
+	case "Input2":
+		lbl = ev.Face2Val
+	default:
+		return nil
+	}
+	pat := ss.Hip.Recall(lbl)
+	if pat == nil { // not a nil *etensor.Float32 in a non-nil interface
+		return nil
+	}
+	return pat
+}
 
-		// Input2 Face Pats
-		pats2 := en.State(ly2.Nm)
-		if pats2 != nil {
-			ly2.ApplyExt(pats2)
-		}
-	}*/
+// HipStore records the settled (minus phase) activity of the Input1, Input2
+// layers in Hip, for the faces of the current trial of given env, along with
+// their original Input states
+func (ss *Sim) HipStore(ev *ExEnv) {
+	for i, lbl := range []string{ev.Face1Val, ev.Face2Val} {
+		lnm := fmt.Sprintf("Input%d", i+1)
+		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		tsr := ss.ValsTsr(lnm)
+		ly.UnitValsTensor(tsr, "ActM")
+		ss.Hip.Store(lbl, tsr, ev.State(lnm).(*etensor.Float32))
+	}
 }
 
 func (ss *Sim) ApplyInputsFace(en env.Env) {
 
 	ss.Net.InitExt()
 
-	lays := []string{"Distance", "Face1", "Face2"}
+	lays := []string{"Distance", "Angle", "Face1", "Face2"}
 	for _, lnm := range lays {
-		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		lly, ok := ss.Net.LayerByName(lnm).(leabra.LeabraLayer)
+		if !ok { // Angle is only in TwoD
+			continue
+		}
+		ly := lly.AsLeabra()
 		pats := en.State(ly.Nm)
 		if pats != nil {
 			ly.ApplyExt(pats)
@@ -642,16 +689,50 @@ func (ss *Sim) TrainTrial() {
 	}
 
 	//ss.InpTarg = true //erand.BoolProb(0.5, -1) //true //always make Input target layer //
-	ss.InpTarg = (rand.Intn(2) == 1)
+	inpTarg := (rand.Intn(2) == 1)
+	inpLayTarg := 0
+	if inpTarg {
+		inpLayTarg = rand.Intn(2) + 1 // if 1, then Inp1 is Target, else if 2, then Inp2 is Target
+	}
+	ss.SetTargLays(inpTarg, inpLayTarg)
 
 	inp1 := ss.Net.LayerByName("Input1").(leabra.LeabraLayer).AsLeabra()
 	inp2 := ss.Net.LayerByName("Input2").(leabra.LeabraLayer).AsLeabra()
 	dist := ss.Net.LayerByName("Distance").(leabra.LeabraLayer).AsLeabra()
 
-	if ss.InpTarg {
-		ss.InpLayTarg = rand.Intn(2) + 1 // if 1, then Inp1 is Target, else if 2, then Inp2 is Target
+	//ss.ApplyInputs(&ss.TrainEnv)
+	if epc > ss.FaceEpc {
+		inp1.SetType(emer.Hidden)
+		inp2.SetType(emer.Hidden)
+		dist.SetType(emer.Target)
+		if ang := ss.Net.LayerByName("Angle"); ang != nil {
+			ang.SetType(emer.Target)
+		}
+		ss.ApplyInputsFace(&ss.TrainEnv)
+		ss.AlphaCyc(true) // train the faces to the relation -- Hip only records the settled Input1, Input2
+		if ss.Hip.Record {
+			ss.HipStore(&ss.TrainEnv)
+		}
+	} else {
+		ss.ApplyInputs(&ss.TrainEnv)
+		ss.AlphaCyc(true)
+	}
+	ss.TrialStats(&ss.TrainEnv, true) // accumulate
+}
+
+// SetTargLays sets InpTarg, InpLayTarg and the layer types accordingly: if
+// inpTarg, then the Input layer given by inpLayTarg (1 or 2) is the target
+// and the others are inputs, otherwise Distance (and Angle in TwoD) is the target
+func (ss *Sim) SetTargLays(inpTarg bool, inpLayTarg int) {
+	ss.InpTarg = inpTarg
+	ss.InpLayTarg = inpLayTarg
+	inp1 := ss.Net.LayerByName("Input1").(leabra.LeabraLayer).AsLeabra()
+	inp2 := ss.Net.LayerByName("Input2").(leabra.LeabraLayer).AsLeabra()
+	dist := ss.Net.LayerByName("Distance").(leabra.LeabraLayer).AsLeabra()
+
+	if inpTarg {
 		dist.SetType(emer.Input)
-		if ss.InpLayTarg == 1 { // Inp1 is Target
+		if inpLayTarg == 1 { // Inp1 is Target
 			inp1.SetType(emer.Target)
 			inp2.SetType(emer.Input)
 		} else { //Inp2 is Target
@@ -659,22 +740,14 @@ func (ss *Sim) TrainTrial() {
 			inp2.SetType(emer.Target)
 		}
 	} else {
+		ss.InpLayTarg = 0
 		inp1.SetType(emer.Input)
 		inp2.SetType(emer.Input)
 		dist.SetType(emer.Target)
 	}
-	//ss.ApplyInputs(&ss.TrainEnv)
-	if epc > 20 {
-		inp1.SetType(emer.Hidden)
-		inp2.SetType(emer.Hidden)
-		dist.SetType(emer.Target)
-		ss.ApplyInputsFace(&ss.TrainEnv)
-		ss.AlphaCyc(true) // don't train for hippocampus
-	} else {
-		ss.ApplyInputs(&ss.TrainEnv)
-		ss.AlphaCyc(true)
+	if ang := ss.Net.LayerByName("Angle"); ang != nil { // the relation is Distance and Angle in TwoD
+		ang.SetType(dist.Type())
 	}
-	ss.TrialStats(true) // accumulate
 }
 
 // RunEnd is called at the end of a run -- save weights, record final log, etc here
@@ -698,6 +771,8 @@ func (ss *Sim) NewRun() {
 	ss.InitStats()
 	ss.TrnEpcLog.SetNumRows(0)
 	ss.TstEpcLog.SetNumRows(0)
+	ss.HipLog.SetNumRows(0)
+	ss.Hip.Init()
 	ss.NeedsNewRun = false
 }
 
@@ -712,6 +787,7 @@ func (ss *Sim) InitStats() {
 	ss.SumCosDiffInp1 = 0
 	ss.SumCosDiffInp2 = 0
 	ss.SumDistError = 0
+	ss.SumAngError = 0
 	ss.SumInp1Error = 0
 	ss.SumInp2Error = 0
 	ss.FirstZero = -1
@@ -732,8 +808,9 @@ func (ss *Sim) InitStats() {
 // accum is true.  Note that we're accumulating stats here on the Sim side so the
 // core algorithm side remains as simple as possible, and doesn't need to worry about
 // different time-scales over which stats could be accumulated etc.
-// You can also aggregate directly from log data, as is done for testing stats
-func (ss *Sim) TrialStats(accum bool) {
+// You can also aggregate directly from log data, as is done for testing stats.
+// The targets are those of given env.
+func (ss *Sim) TrialStats(ev *ExEnv, accum bool) {
 	inp1 := ss.Net.LayerByName("Input1").(leabra.LeabraLayer).AsLeabra()
 	inp2 := ss.Net.LayerByName("Input2").(leabra.LeabraLayer).AsLeabra()
 	dist := ss.Net.LayerByName("Distance").(leabra.LeabraLayer).AsLeabra()
@@ -747,11 +824,15 @@ func (ss *Sim) TrialStats(accum bool) {
 
 			inp1tsr := ss.ValsTsr(inp1.Nm)
 			inp1.UnitValsTensor(inp1tsr, "ActM")
-			inp1Val := ss.TrainEnv.Input1Pop.Decode(inp1tsr.Values)
-			targInp1 := ss.TrainEnv.Inp1Val
-			inp1Error := math.Abs(float64(inp1Val - targInp1))
-			ss.Input1Error = float64(inp1Error) / float64(ss.TrainEnv.MaxInp)
-			ss.Inp1Value = float64(inp1Val)
+			if ev.TwoD {
+				ss.Input1Error = ss.PosError(ev, inp1tsr, ev.Pos1)
+			} else {
+				inp1Val := ev.Input1Pop.Decode(inp1tsr.Values)
+				targInp1 := ev.Inp1Val
+				inp1Error := math.Abs(float64(inp1Val - targInp1))
+				ss.Input1Error = float64(inp1Error) / float64(ev.MaxInp)
+				ss.Inp1Value = float64(inp1Val)
+			}
 		} else { // Inp2 is Target
 			ss.TrlCosDiff = float64(inp2.CosDiff.Cos)
 			inp2_s, inp2_a := inp2.MSE(0.5)
@@ -760,17 +841,26 @@ func (ss *Sim) TrialStats(accum bool) {
 
 			inp2tsr := ss.ValsTsr(inp2.Nm)
 			inp2.UnitValsTensor(inp2tsr, "ActM")
-			inp2Val := ss.TrainEnv.Input2Pop.Decode(inp2tsr.Values)
-			targInp2 := ss.TrainEnv.Inp2Val
-			inp2Error := math.Abs(float64(inp2Val - targInp2))
-			ss.Input2Error = float64(inp2Error) / float64(ss.TrainEnv.MaxInp)
-			ss.Inp2Value = float64(inp2Val)
+			if ev.TwoD {
+				ss.Input2Error = ss.PosError(ev, inp2tsr, ev.Pos2)
+			} else {
+				inp2Val := ev.Input2Pop.Decode(inp2tsr.Values)
+				targInp2 := ev.Inp2Val
+				inp2Error := math.Abs(float64(inp2Val - targInp2))
+				ss.Input2Error = float64(inp2Error) / float64(ev.MaxInp)
+				ss.Inp2Value = float64(inp2Val)
+			}
 		}
 	} else { //Distance is Target
 		ss.TrlCosDiff = float64(dist.CosDiff.Cos)
 		dist_s, dist_a := dist.MSE(0.5)
 		ss.TrlSSE = dist_s
 		ss.TrlAvgSSE = dist_a
+		if ang := ss.Net.LayerByName("Angle"); ang != nil {
+			ang_s, ang_a := ang.(leabra.LeabraLayer).AsLeabra().MSE(0.5)
+			ss.TrlSSE += ang_s
+			ss.TrlAvgSSE = (dist_a + ang_a) / 2
+		}
 	}
 
 	ss.TrlCosDiffInp1 = float64(inp1.CosDiff.Cos)
@@ -778,10 +868,19 @@ func (ss *Sim) TrialStats(accum bool) {
 
 	dtsr := ss.ValsTsr(dist.Nm)
 	dist.UnitValsTensor(dtsr, "ActM")
-	distVal := ss.TrainEnv.DistPop.Decode(dtsr.Values)
-	targDist := ss.TrainEnv.DistVal
+	distVal := ev.DistPop.Decode(dtsr.Values)
+	targDist := ev.DistVal
 	distError := math.Abs(float64(distVal - targDist))
-	ss.DistanceError = float64(distError) / float64(ss.TrainEnv.MaxDist)
+	ss.DistanceError = float64(distError) / float64(ev.MaxDist)
+
+	ss.AngleError = 0
+	if ev.TwoD {
+		ang := ss.Net.LayerByName("Angle").(leabra.LeabraLayer).AsLeabra()
+		atsr := ss.ValsTsr(ang.Nm)
+		ang.UnitValsTensor(atsr, "ActM")
+		angVal := ev.AnglePop.Decode(atsr.Values)
+		ss.AngleError = float64(geom.AngleDist(angVal, ev.AngVal)) / 360
+	}
 
 	/* input1tsr := ss.ValsTsr(inp1.Nm)
 	inp1.UnitValsTensor(input1tsr, "ActM")
@@ -811,11 +910,23 @@ func (ss *Sim) TrialStats(accum bool) {
 		ss.SumCosDiffInp1 += ss.TrlCosDiffInp1
 		ss.SumCosDiffInp2 += ss.TrlCosDiffInp2
 		ss.SumDistError += ss.DistanceError
+		ss.SumAngError += ss.AngleError
 		ss.SumInp1Error += ss.Input1Error
 		ss.SumInp2Error += ss.Input2Error
 	}
 }
 
+// PosError returns the error of the position decoded from given Input1 or
+// Input2 activity pattern in TwoD, relative to position pos, normalized by
+// MaxDist, the diagonal of the grid
+func (ss *Sim) PosError(ev *ExEnv, pat *etensor.Float32, pos mat32.Vec2) float64 {
+	perr, err := ev.PosError(pat, pos)
+	if err != nil {
+		log.Println(err)
+	}
+	return float64(perr) / float64(ev.MaxDist)
+}
+
 // TrainEpoch runs training trials for remainder of this epoch
 func (ss *Sim) TrainEpoch() {
 	ss.StopNow = false
@@ -904,9 +1015,10 @@ func (ss *Sim) TestTrial(returnOnChg bool) {
 		}
 	}
 
+	ss.SetTargLays(false, 0) // always test decoding of Distance
 	ss.ApplyInputs(&ss.TestEnv)
-	ss.AlphaCyc(false)   // !train
-	ss.TrialStats(false) // !accumulate
+	ss.AlphaCyc(false)                // !train
+	ss.TrialStats(&ss.TestEnv, false) // !accumulate
 	ss.LogTstTrl(ss.TstTrlLog)
 }
 
@@ -1052,6 +1164,8 @@ func (ss *Sim) LogTrnEpc(dt *etable.Table) {
 	ss.SumCosDiffInp2 = 0
 	ss.EpcDistError = ss.SumDistError / nt
 	ss.SumDistError = 0
+	ss.EpcAngError = ss.SumAngError / nt
+	ss.SumAngError = 0
 	ss.EpcInp1Error = ss.SumInp1Error / nt
 	ss.SumInp1Error = 0
 	ss.EpcInp2Error = ss.SumInp2Error / nt
@@ -1084,8 +1198,10 @@ func (ss *Sim) LogTrnEpc(dt *etable.Table) {
 	dt.SetCellFloat("CosDiffInp2", row, ss.EpcCosDiffInp2)
 	dt.SetCellFloat("PerTrlMSec", row, ss.EpcPerTrlMSec)
 	dt.SetCellFloat("EpcDistError", row, ss.EpcDistError)
+	dt.SetCellFloat("EpcAngError", row, ss.EpcAngError)
 	dt.SetCellFloat("EpcInp1Error", row, ss.EpcInp1Error)
 	dt.SetCellFloat("EpcInp2Error", row, ss.EpcInp2Error)
+	dt.SetCellFloat("HipFidelity", row, float64(ss.Hip.MeanFidelity()))
 
 	for _, lnm := range ss.LayStatNms {
 		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
@@ -1100,6 +1216,7 @@ func (ss *Sim) LogTrnEpc(dt *etable.Table) {
 		}
 		dt.WriteCSVRow(ss.TrnEpcFile, row, etable.Tab)
 	}
+	ss.LogHip(ss.HipLog)
 }
 
 func (ss *Sim) ConfigTrnEpcLog(dt *etable.Table) {
@@ -1120,8 +1237,10 @@ func (ss *Sim) ConfigTrnEpcLog(dt *etable.Table) {
 		{"CosDiffInp2", etensor.FLOAT64, nil, nil},
 		{"PerTrlMSec", etensor.FLOAT64, nil, nil},
 		{"EpcDistError", etensor.FLOAT64, nil, nil},
+		{"EpcAngError", etensor.FLOAT64, nil, nil},
 		{"EpcInp1Error", etensor.FLOAT64, nil, nil},
 		{"EpcInp2Error", etensor.FLOAT64, nil, nil},
+		{"HipFidelity", etensor.FLOAT64, nil, nil},
 	}
 	for _, lnm := range ss.LayStatNms {
 		sch = append(sch, etable.Column{lnm + " ActAvg", etensor.FLOAT64, nil, nil})
@@ -1148,6 +1267,7 @@ func (ss *Sim) ConfigTrnEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 	plt.SetColParams("EpcCosDiffInp1", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
 	plt.SetColParams("EpcCosDiffInp2", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
 	plt.SetColParams("EpcAngError", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("HipFidelity", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
 	plt.SetColParams("EpcEgoCosDiff", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
 	plt.SetColParams("EpcAlloCosDiff", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
 
@@ -1157,6 +1277,43 @@ func (ss *Sim) ConfigTrnEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 	return plt
 }
 
+//////////////////////////////////////////////
+//  HipLog
+
+// LogHip adds the current contents of Hip to the HipLog table, one row per
+// recorded face in the order of the Labels, with its fidelity
+func (ss *Sim) LogHip(dt *etable.Table) {
+	epc := ss.TrainEnv.Epoch.Prv // this is triggered by increment so use previous value
+	for _, lbl := range ss.TrainEnv.Labels {
+		if !ss.Hip.Has(lbl) {
+			continue
+		}
+		row := dt.Rows
+		dt.SetNumRows(row + 1)
+		dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+		dt.SetCellFloat("Epoch", row, float64(epc))
+		dt.SetCellString("Face", row, lbl)
+		dt.SetCellFloat("Fidelity", row, float64(ss.Hip.Fidelity(lbl)))
+		dt.SetCellTensor("Pattern", row, ss.Hip.Recall(lbl))
+	}
+}
+
+func (ss *Sim) ConfigHipLog(dt *etable.Table) {
+	dt.SetMetaData("name", "HipLog")
+	dt.SetMetaData("desc", "Record of the Input representations of the faces in Hip, by epoch of training")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	inp := ss.Net.LayerByName("Input1").(leabra.LeabraLayer).AsLeabra()
+	dt.SetFromSchema(etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Face", etensor.STRING, nil, nil},
+		{"Fidelity", etensor.FLOAT64, nil, nil},
+		{"Pattern", etensor.FLOAT32, inp.Shp.Shp, nil},
+	}, 0)
+}
+
 //////////////////////////////////////////////
 //  TstTrlLog
 
@@ -1175,11 +1332,23 @@ func (ss *Sim) LogTstTrl(dt *etable.Table) {
 	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
 	dt.SetCellFloat("Epoch", row, float64(epc))
 	dt.SetCellFloat("Trial", row, float64(trl))
-	dt.SetCellString("TrialName", row, ss.TestEnv.String(ss.InpTarg, ss.InpLayTarg, ss.TrainEnv.Face1Val, ss.TrainEnv.Face2Val))
+	dt.SetCellString("TrialName", row, ss.TestEnv.String(ss.InpTarg, ss.InpLayTarg, ss.TestEnv.Face1Val, ss.TestEnv.Face2Val))
 	dt.SetCellFloat("Err", row, ss.TrlErr)
 	dt.SetCellFloat("SSE", row, ss.TrlSSE)
 	dt.SetCellFloat("AvgSSE", row, ss.TrlAvgSSE)
 	dt.SetCellFloat("CosDiff", row, ss.TrlCosDiff)
+	dt.SetCellFloat("DistError", row, ss.DistanceError)
+	dt.SetCellFloat("AngError", row, ss.AngleError)
+
+	// symbolic distance of the pair, and whether TrainEnv presents it
+	inp1, inp2 := int(ss.TestEnv.Inp1Val), int(ss.TestEnv.Inp2Val)
+	dt.SetCellFloat("SymDist", row, float64(SymDist(inp1, inp2)))
+	trained := 0.0
+	if ss.TrainEnv.HasPair(inp1, inp2) {
+		trained = 1
+	}
+	dt.SetCellFloat("Trained", row, trained)
+	dt.SetCellFloat("FaceSim", row, float64(ss.TestEnv.FaceSims.Value([]int{inp1, inp2})))
 
 	for _, lnm := range ss.LayStatNms {
 		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
@@ -1208,6 +1377,11 @@ func (ss *Sim) ConfigTstTrlLog(dt *etable.Table) {
 		{"SSE", etensor.FLOAT64, nil, nil},
 		{"AvgSSE", etensor.FLOAT64, nil, nil},
 		{"CosDiff", etensor.FLOAT64, nil, nil},
+		{"DistError", etensor.FLOAT64, nil, nil},
+		{"AngError", etensor.FLOAT64, nil, nil},
+		{"SymDist", etensor.INT64, nil, nil},
+		{"Trained", etensor.FLOAT64, nil, nil},
+		{"FaceSim", etensor.FLOAT64, nil, nil},
 	}
 	for _, lnm := range ss.LayStatNms {
 		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
@@ -1229,6 +1403,11 @@ func (ss *Sim) ConfigTstTrlPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 	plt.SetColParams("SSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
 	plt.SetColParams("AvgSSE", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 0)
 	plt.SetColParams("CosDiff", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("DistError", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("AngError", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("SymDist", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Trained", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("FaceSim", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
 
 	for _, lnm := range ss.LayStatNms {
 		plt.SetColParams(lnm+" Act", eplot.Off, eplot.FixMin, 0, eplot.FixMax, .5)
@@ -1257,6 +1436,31 @@ func (ss *Sim) LogTstEpc(dt *etable.Table) {
 	dt.SetCellFloat("PctErr", row, agg.Mean(tix, "Err")[0])
 	dt.SetCellFloat("PctCor", row, 1-agg.Mean(tix, "Err")[0])
 	dt.SetCellFloat("CosDiff", row, agg.Mean(tix, "CosDiff")[0])
+	dt.SetCellFloat("DistError", row, agg.Mean(tix, "DistError")[0])
+	dt.SetCellFloat("AngError", row, agg.Mean(tix, "AngError")[0])
+
+	// trained vs. untrained pairs -- with AdjacentPairs training, the
+	// untrained pairs are the remote pairs inferred transitively
+	trnix := etable.NewIdxView(trl)
+	trnix.Filter(func(et *etable.Table, row int) bool {
+		return et.CellFloat("Trained", row) == 1
+	})
+	untrnix := etable.NewIdxView(trl)
+	untrnix.Filter(func(et *etable.Table, row int) bool {
+		return et.CellFloat("Trained", row) == 0
+	})
+	dt.SetCellFloat("DistErrTrained", row, agg.Mean(trnix, "DistError")[0])
+	dt.SetCellFloat("DistErrUntrained", row, agg.Mean(untrnix, "DistError")[0])
+
+	// distance error for each symbolic distance -- the symbolic distance effect
+	for sd := 1; sd < ss.TestEnv.NItems; sd++ {
+		sdf := float64(sd)
+		sdix := etable.NewIdxView(trl)
+		sdix.Filter(func(et *etable.Table, row int) bool {
+			return et.CellFloat("SymDist", row) == sdf
+		})
+		dt.SetCellFloat("DistErr_"+SymDistColName(sd), row, agg.Mean(sdix, "DistError")[0])
+	}
 
 	trlix := etable.NewIdxView(trl)
 	trlix.Filter(func(et *etable.Table, row int) bool {
@@ -1280,7 +1484,7 @@ func (ss *Sim) ConfigTstEpcLog(dt *etable.Table) {
 	dt.SetMetaData("read-only", "true")
 	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
 
-	dt.SetFromSchema(etable.Schema{
+	sch := etable.Schema{
 		{"Run", etensor.INT64, nil, nil},
 		{"Epoch", etensor.INT64, nil, nil},
 		{"SSE", etensor.FLOAT64, nil, nil},
@@ -1288,7 +1492,21 @@ func (ss *Sim) ConfigTstEpcLog(dt *etable.Table) {
 		{"PctErr", etensor.FLOAT64, nil, nil},
 		{"PctCor", etensor.FLOAT64, nil, nil},
 		{"CosDiff", etensor.FLOAT64, nil, nil},
-	}, 0)
+		{"DistError", etensor.FLOAT64, nil, nil},
+		{"AngError", etensor.FLOAT64, nil, nil},
+		{"DistErrTrained", etensor.FLOAT64, nil, nil},
+		{"DistErrUntrained", etensor.FLOAT64, nil, nil},
+	}
+	for sd := 1; sd < ss.TestEnv.NItems; sd++ {
+		sch = append(sch, etable.Column{"DistErr_" + SymDistColName(sd), etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+// SymDistColName returns the suffix of the per-symbolic-distance columns
+// of the TstEpcLog for given symbolic distance, e.g., d2
+func SymDistColName(sd int) string {
+	return fmt.Sprintf("d%d", sd)
 }
 
 func (ss *Sim) ConfigTstEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
@@ -1303,6 +1521,13 @@ func (ss *Sim) ConfigTstEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 	plt.SetColParams("PctErr", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1) // default plot
 	plt.SetColParams("PctCor", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1) // default plot
 	plt.SetColParams("CosDiff", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("DistError", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("AngError", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("DistErrTrained", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("DistErrUntrained", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	for sd := 1; sd < ss.TestEnv.NItems; sd++ {
+		plt.SetColParams("DistErr_"+SymDistColName(sd), eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
+	}
 	return plt
 }
 
@@ -1387,6 +1612,7 @@ func (ss *Sim) LogRun(dt *etable.Table) {
 	dt.SetCellFloat("PctErr", row, agg.Mean(epcix, "PctErr")[0])
 	dt.SetCellFloat("PctCor", row, agg.Mean(epcix, "PctCor")[0])
 	dt.SetCellFloat("CosDiff", row, agg.Mean(epcix, "CosDiff")[0])
+	dt.SetCellFloat("FaceSim", row, float64(MeanOffDiag(ss.TrainEnv.FaceSims)))
 
 	runix := etable.NewIdxView(dt)
 	spl := split.GroupBy(runix, []string{"Params"})
@@ -1419,6 +1645,7 @@ func (ss *Sim) ConfigRunLog(dt *etable.Table) {
 		{"PctErr", etensor.FLOAT64, nil, nil},
 		{"PctCor", etensor.FLOAT64, nil, nil},
 		{"CosDiff", etensor.FLOAT64, nil, nil},
+		{"FaceSim", etensor.FLOAT64, nil, nil},
 	}, 0)
 }
 
@@ -1694,6 +1921,11 @@ func (ss *Sim) CmdArgs() {
 	flag.StringVar(&ss.ParamSet, "params", "", "ParamSet name to use -- must be valid name as listed in compiled-in params or loaded params")
 	flag.StringVar(&ss.Tag, "tag", "", "extra tag to add to file names saved from this run")
 	flag.StringVar(&note, "note", "", "user note -- describe the run params etc")
+	flag.IntVar(&ss.NItems, "items", 4, "number of items in the ordering of faces, e.g., 5, 7 or 10")
+	flag.StringVar(&ss.FaceCodes, "faces", "", "distributed face identity codes, e.g., size:40;act:0.2;overlap:0.25 -- one-hot if empty")
+	flag.BoolVar(&ss.TwoD, "twod", false, "if true, faces have positions in a 2D social space, decoded as Distance and Angle")
+	flag.IntVar(&ss.FaceEpc, "faceepc", 20, "after this epoch, training presents the faces, and Hip records their Input representations")
+	flag.BoolVar(&ss.Hip.Retrieve, "hipretrieve", false, "if true, Input1, Input2 get the Input representations of the faces recorded in Hip, instead of their Input codes")
 	flag.IntVar(&ss.MaxRuns, "runs", 10, "number of runs to do (note that MaxEpcs is in paramset)")
 	flag.BoolVar(&ss.LogSetParams, "setparams", false, "if true, print a record of each parameter that is set")
 	flag.BoolVar(&ss.SaveWts, "wts", false, "if true, save final weights after each run")
@@ -1701,7 +1933,7 @@ func (ss *Sim) CmdArgs() {
 	flag.BoolVar(&saveRunLog, "runlog", true, "if true, save run epoch log to file")
 	flag.BoolVar(&nogui, "nogui", true, "if not passing any other args and want to run nogui, use nogui")
 	flag.Parse()
-	ss.Init()
+	ss.Init() // rebuilds the network if the items, TwoD or face codes change the layer sizes
 
 	if note != "" {
 		fmt.Printf("note: %s\n", note)
@@ -1709,6 +1941,13 @@ func (ss *Sim) CmdArgs() {
 	if ss.ParamSet != "" {
 		fmt.Printf("Using ParamSet: %s\n", ss.ParamSet)
 	}
+	fmt.Printf("Using %d items: %v\n", ss.NItems, ss.TrainEnv.Labels)
+	if ss.TwoD {
+		fmt.Printf("Item positions in 2D: %v\n", ss.TrainEnv.Positions)
+	}
+	if ss.FaceCodes != "" {
+		fmt.Printf("Using face codes: %v, mean similarity: %g\n", ss.TrainEnv.Faces.String(), MeanOffDiag(ss.TrainEnv.FaceSims))
+	}
 
 	if saveEpcLog {
 		var err error
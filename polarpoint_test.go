@@ -2,12 +2,14 @@ package main
 
 import (
 	"fmt"
+	"image"
 	"math"
 	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/goki/mat32"
+	"myEnv/geom"
 )
 
 func TestPoints(t *testing.T) {
@@ -22,10 +24,10 @@ func TestPoints(t *testing.T) {
 	MinDist := float32(4)
 	dist := MinDist + rand.Float32()*(float32(MaxDist)-MinDist+1)
 	ang := rand.Float32() * 360
-	Point2X := int(math.Abs(float64(dist*mat32.Cos(ang*math.Pi/180)))) + PointX
-	Point2Y := int(math.Abs(float64(dist*mat32.Sin(ang*math.Pi/180)))) + PointY
-	hypotDist := float32(math.Hypot(float64(Point2X-PointX), float64(Point2Y-PointY)))
-	actualAng := 180 * math.Atan2(float64(Point2Y-PointY), float64(Point2X-PointX)) / math.Pi
+	d := geom.PolarVec(dist, ang)
+	Point2X := int(mat32.Abs(d.X)) + PointX
+	Point2Y := int(mat32.Abs(d.Y)) + PointY
+	hypotDist, actualAng := geom.Polar(geom.LatticeVec(image.Point{Point2X - PointX, Point2Y - PointY}))
 	fmt.Println("Point1: ", PointX, PointY)
 	fmt.Println("Point2: ", Point2X, Point2Y)
 	fmt.Println("Dist, , HypotDist, Ang, AngActual: ", dist, hypotDist, ang, actualAng)
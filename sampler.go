@@ -0,0 +1,168 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/goki/mat32"
+	"myEnv/geom"
+)
+
+// DispSampler samples the displacement vector between Point and Point2
+// for each new trial in ExEnv.  Different samplers correspond to different
+// training distributions over relations.
+type DispSampler interface {
+	// Name returns the name used to select this sampler, e.g., on the command line
+	Name() string
+
+	// Sample returns a new random displacement, which must satisfy ev.DispOk --
+	// it can assume that Disps is not empty, as checked by ExEnv.Validate
+	Sample(ev *ExEnv) image.Point
+
+	// Disps returns every displacement that Sample can produce, in a fixed order
+	Disps(ev *ExEnv) []image.Point
+}
+
+// ContDispSampler is a DispSampler that can also sample real-valued
+// displacements directly, for ExEnv.Continuous
+type ContDispSampler interface {
+	DispSampler
+
+	// SampleVec returns a new random displacement, which must satisfy ev.DispVecOk
+	SampleVec(ev *ExEnv) mat32.Vec2
+}
+
+// DispSamplers are all the built-in samplers, in order of selection name
+var DispSamplers = []DispSampler{&AxisSampler{}, &CompassSampler{}, &LatticeSampler{}, &AngleSampler{}}
+
+// DispSamplerByName returns the built-in sampler with given name
+func DispSamplerByName(nm string) (DispSampler, error) {
+	nms := make([]string, len(DispSamplers))
+	for i, ds := range DispSamplers {
+		if ds.Name() == nm {
+			return ds, nil
+		}
+		nms[i] = ds.Name()
+	}
+	return nil, fmt.Errorf("DispSamplerByName: sampler named: %v not found -- must be one of: %v", nm, strings.Join(nms, ", "))
+}
+
+// AxisSampler samples purely horizontal or purely vertical displacements,
+// uniformly over direction and magnitude in ExEnv.DispRange, among those
+// that satisfy ExEnv.DispOk, e.g., with bearings less than MaxAngle
+type AxisSampler struct {
+}
+
+func (ds *AxisSampler) Name() string { return "axis" }
+
+func (ds *AxisSampler) Sample(ev *ExEnv) image.Point {
+	ok := ds.Disps(ev)
+	return ok[ev.Rand.Intn(len(ok))]
+}
+
+func (ds *AxisSampler) Disps(ev *ExEnv) []image.Point {
+	var ok []image.Point
+	for _, d := range ev.LatticeDisps() {
+		if d.X == 0 || d.Y == 0 {
+			ok = append(ok, d)
+		}
+	}
+	return ok
+}
+
+// CompassDirs are the 8 compass directions as unit lattice steps
+var CompassDirs = []image.Point{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+
+// CompassSampler samples displacements along the 8 compass directions
+// (horizontal, vertical and diagonal), uniformly over direction and then
+// over the number of steps that keeps the displacement within the
+// MinDist..MaxDist band
+type CompassSampler struct {
+}
+
+func (ds *CompassSampler) Name() string { return "compass" }
+
+func (ds *CompassSampler) Sample(ev *ExEnv) image.Point {
+	for {
+		dir := CompassDirs[ev.Rand.Intn(len(CompassDirs))]
+		var ok []image.Point
+		for k := 1; k < ev.Size; k++ {
+			d := dir.Mul(k)
+			if ev.DispOk(d) {
+				ok = append(ok, d)
+			}
+		}
+		if len(ok) > 0 {
+			return ok[ev.Rand.Intn(len(ok))]
+		}
+	}
+}
+
+func (ds *CompassSampler) Disps(ev *ExEnv) []image.Point {
+	var ok []image.Point
+	for _, d := range ev.LatticeDisps() {
+		if d.X == 0 || d.Y == 0 || d.X == d.Y || d.X == -d.Y {
+			ok = append(ok, d)
+		}
+	}
+	return ok
+}
+
+// LatticeSampler samples uniformly over all the lattice vectors whose length
+// is within the MinDist..MaxDist band
+type LatticeSampler struct {
+}
+
+func (ds *LatticeSampler) Name() string { return "lattice" }
+
+func (ds *LatticeSampler) Sample(ev *ExEnv) image.Point {
+	ok := ev.LatticeDisps()
+	return ok[ev.Rand.Intn(len(ok))]
+}
+
+func (ds *LatticeSampler) Disps(ev *ExEnv) []image.Point {
+	return ev.LatticeDisps()
+}
+
+// AngleSampler samples a continuous angle uniformly over 0..360 and a
+// distance uniformly over the MinDist..MaxDist band, rounding the resulting
+// vector to the nearest lattice point -- resamples if rounding takes it
+// out of the band
+type AngleSampler struct {
+}
+
+func (ds *AngleSampler) Name() string { return "angle" }
+
+func (ds *AngleSampler) Sample(ev *ExEnv) image.Point {
+	for {
+		dist := float64(ev.MinDist) + ev.Rand.Float64()*(float64(ev.MaxDist)-float64(ev.MinDist))
+		ang := ev.Rand.Float64() * 360
+		d := geom.LatticePoint(geom.PolarVec(float32(dist), float32(ang)))
+		if ev.DispOk(d) {
+			return d
+		}
+	}
+}
+
+// SampleVec samples the same angle and distance as Sample, without rounding
+func (ds *AngleSampler) SampleVec(ev *ExEnv) mat32.Vec2 {
+	for {
+		dist := float64(ev.MinDist) + ev.Rand.Float64()*(float64(ev.MaxDist)-float64(ev.MinDist))
+		ang := ev.Rand.Float64() * 360
+		d := geom.PolarVec(float32(dist), float32(ang))
+		if ev.DispVecOk(d) {
+			return d
+		}
+	}
+}
+
+// Disps returns all the lattice displacements in the band, as each of them
+// is the rounded result of its own exact angle and distance
+func (ds *AngleSampler) Disps(ev *ExEnv) []image.Point {
+	return ev.LatticeDisps()
+}
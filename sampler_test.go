@@ -0,0 +1,103 @@
+package main
+
+import (
+	"image"
+	"testing"
+
+	"github.com/emer/emergent/env"
+	"myEnv/geom"
+)
+
+func TestDispSamplers(t *testing.T) {
+	for _, ds := range DispSamplers {
+		ev := ExEnv{}
+		ev.Config(9, 10)
+		if err := ev.SetSampler(ds.Name()); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 500; i++ {
+			ev.NewPoint()
+			d := geom.LatticePoint(ev.Point2.Sub(ev.Point))
+			if !ev.DispOk(d) {
+				t.Errorf("%v: displacement %v out of band", ds.Name(), d)
+			}
+		}
+	}
+	ev := ExEnv{}
+	if err := ev.SetSampler("bogus"); err == nil {
+		t.Errorf("expected error for unknown sampler name")
+	}
+}
+
+func TestEnumTrials(t *testing.T) {
+	for _, ds := range DispSamplers {
+		ev := ExEnv{}
+		ev.Config(9, 10)
+		ev.SetSampler(ds.Name())
+		ev.Enum = true
+		ev.Init(0)
+		if ev.Trial.Max != len(ev.Trials) || ev.Trial.Max == 0 {
+			t.Fatalf("%v: Trial.Max: %d, n trials: %d", ds.Name(), ev.Trial.Max, len(ev.Trials))
+		}
+		seen := map[PointTrial]bool{}
+		for i := 0; i < ev.Trial.Max; i++ {
+			ev.Step()
+			tr := PointTrial{geom.LatticePoint(ev.Point), geom.LatticePoint(ev.Point2.Sub(ev.Point)), geom.LatticePoint(ev.InDisp), ev.Xform, ev.ScaleVal}
+			if tr != ev.Trials[i] {
+				t.Errorf("%v: trial %d out of order: %v vs. %v", ds.Name(), i, tr, ev.Trials[i])
+			}
+			if seen[tr] {
+				t.Errorf("%v: trial %v repeated", ds.Name(), tr)
+			}
+			seen[tr] = true
+		}
+		ev.Step() // wraps around to the first trial of the next epoch
+		if _, _, chg := ev.Counter(env.Epoch); !chg {
+			t.Errorf("%v: epoch did not advance after %d trials", ds.Name(), ev.Trial.Max)
+		}
+		if geom.LatticePoint(ev.Point) != ev.Trials[0].Start {
+			t.Errorf("%v: next epoch did not restart at first trial", ds.Name())
+		}
+	}
+}
+
+// noDisps is a sampler with no displacements, which Validate must reject
+type noDisps struct {
+	LatticeSampler
+}
+
+func (ds *noDisps) Disps(ev *ExEnv) []image.Point { return nil }
+
+func TestNoDisps(t *testing.T) {
+	ev := ExEnv{}
+	ev.Config(9, 10)
+	ev.Disp = &noDisps{}
+	if err := ev.Validate(); err == nil {
+		t.Errorf("expected error for a sampler with no displacements")
+	}
+	ev.SetSampler("axis")
+	ev.MaxAngle = 90
+	for i := 0; i < 200; i++ {
+		if d := ev.Disp.Sample(&ev); !ev.DispOk(d) {
+			t.Errorf("axis: displacement %v does not satisfy DispOk with MaxAngle %d", d, ev.MaxAngle)
+		}
+	}
+}
+
+func TestEnumNoTrials(t *testing.T) {
+	ev := ExEnv{}
+	ev.Config(9, 10)
+	ev.Enum = true
+	ev.ExclHeld = true
+	ev.HoldOut.Sectors = []AngleSector{{0, 360}} // every bearing
+	if err := ev.Validate(); err == nil {
+		t.Errorf("expected error when every trial is held out")
+	}
+	if err := ev.EnumTrials(); err == nil {
+		t.Errorf("expected error from EnumTrials with no trials")
+	}
+	ev.Init(0)
+	if ev.Step() {
+		t.Errorf("Step with no trials to enumerate should return false")
+	}
+}
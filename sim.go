@@ -35,6 +35,8 @@ import (
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
 	"github.com/goki/mat32"
+	"myEnv/envcheck"
+	"myEnv/geom"
 )
 
 func main() {
@@ -96,6 +98,21 @@ var ParamSets = params.Sets{
 				}},
 		},
 	}},
+	{Name: "FineAngle", Desc: "finer Angle and Distance codes -- env sheets set the encoding, and the layers are sized from it", Sheets: params.Sheets{
+		"TrainEnv": FineAngleEnv,
+		"TestEnv":  FineAngleEnv,
+	}},
+}
+
+// FineAngleEnv is the env sheet of the FineAngle ParamSet, used for both the
+// TrainEnv and TestEnv sheets, as all the envs must match the same layers
+var FineAngleEnv = &params.Sheet{
+	{Sel: "ExEnv", Desc: "twice the units, with narrower tuning",
+		Params: params.Params{
+			"ExEnv.NAngleUnits":   "48",
+			"ExEnv.NDistUnits":    "20",
+			"ExEnv.DistPop.Sigma": "0.1",
+		}},
 }
 
 // Sim encapsulates the entire simulation model, and we define all the
@@ -107,11 +124,14 @@ type Sim struct {
 	Size         int               `desc:"size of each dim in 2D input"`
 	Net          *leabra.Network   `view:"no-inline" desc:"the network -- click to view / edit parameters for layers, prjns, etc"`
 	TrnEpcLog    *etable.Table     `view:"no-inline" desc:"training epoch-level log data"`
+	TrnTrlLog    *etable.Table     `view:"no-inline" desc:"every trial generated for training in the current run, when SaveTrnTrls"`
 	TstEpcLog    *etable.Table     `view:"no-inline" desc:"testing epoch-level log data"`
 	TstTrlLog    *etable.Table     `view:"no-inline" desc:"testing trial-level log data"`
 	TstErrLog    *etable.Table     `view:"no-inline" desc:"log of all test trials where errors were made"`
 	TstErrStats  *etable.Table     `view:"no-inline" desc:"stats on test trials where errors were made"`
 	TstCycLog    *etable.Table     `view:"no-inline" desc:"testing cycle-level log data"`
+	XformTrlLog  *etable.Table     `view:"no-inline" desc:"transform test trial-level log data"`
+	XformEpcLog  *etable.Table     `view:"no-inline" desc:"transform test summary log data, with the angle error for each transform"`
 	RunLog       *etable.Table     `view:"no-inline" desc:"summary log of each run"`
 	RunStats     *etable.Table     `view:"no-inline" desc:"aggregate stats on all runs"`
 	Params       params.Sets       `view:"no-inline" desc:"full collection of param sets"`
@@ -120,8 +140,31 @@ type Sim struct {
 	MaxRuns      int               `desc:"maximum number of model runs to perform"`
 	MaxEpcs      int               `desc:"maximum number of epochs to run per model run"`
 	NZeroStop    int               `desc:"if a positive number, training will stop after this many epochs with zero SSE"`
-	TrainEnv     ExEnv             `desc:"Training environment -- contains everything about iterating over input / output patterns over training"`
-	TestEnv      ExEnv             `desc:"Testing environment -- manages iterating over testing"`
+	TrainEnv     TableEnv          `desc:"Training environment -- contains everything about iterating over input / output patterns over training"`
+	TestEnv      TableEnv          `desc:"Testing environment -- manages iterating over testing"`
+	TrialsFile   string            `desc:"if set, a TSV or CSV file of trials that both environments present, instead of generating them -- see OpenTrials, SplitTrials"`
+	Trials       *etable.Table     `view:"no-inline" desc:"trials loaded from TrialsFile"`
+	XformEnv     ExEnv             `desc:"transform test environment -- tests every trial under each of the Xforms transforms, which are cued on XformCue"`
+	Xforms       string            `desc:"comma-separated names of the Transforms tested by XformEnv, e.g., rot90,reflx,rot:30 -- the inputs are untransformed, the targets transformed, and XformCue tells which transform relates them, see Transform"`
+	XformTest    bool              `desc:"if true, the transform test suite is run along with the regular test at every TestInterval"`
+	SaveTrnTrls  bool              `desc:"if true, every trial generated for training is recorded in TrnTrlLog, and saved to a separate file for each run"`
+	NDistract    int               `desc:"number of distractor points added to AlloInput in all environments -- the network has to select the partner of the Attn point by the feature cued on FeatCue"`
+	Sampler      string            `desc:"name of the displacement sampler used by both environments -- axis, compass, lattice or angle"`
+	Continuous   bool              `desc:"if true, training points and displacements are real-valued, off the integer lattice -- testing still goes through every lattice trial"`
+	Wrap         bool              `desc:"if true, the grid of all environments is a torus that positions wrap around, so that every start point is paired with every displacement"`
+	Scales       string            `desc:"comma-separated scale factors of the source displacement in the scale analogies posed by TrainEnv and TestEnv, cued on ScaleCue, e.g., 0.5,2,3 -- empty for none"`
+	HeadingOn    bool              `desc:"if true, the agent has a random heading in all environments, presented on the Heading layer, and EgoInput is in the frame of the agent"`
+	HeadingStep  float32           `desc:"headings are multiples of this many degrees -- 0 for any heading"`
+	EgoAngle     bool              `desc:"if true, the Angle target is the egocentric bearing, relative to the heading, instead of the allocentric bearing"`
+	HoldOut      string            `desc:"spec of the trials held out of training and reported separately in testing -- see ParseHoldOut, e.g., disp:4,0;region:0,0,3,3;sector:30,60"`
+	TrainCorrupt string            `desc:"spec of the corruption of the training inputs -- see ParseCorruption, e.g., noise:0.1;dropout:0.2;occlude:3;jitter:0.5"`
+	TestCorrupt  string            `desc:"spec of the corruption of the testing inputs, to test robustness -- can also be set directly on TestEnv.Corrupt to sweep it between tests"`
+	Curric       string            `desc:"spec of the stages of the training curriculum, which widens the distance band and bearings of TrainEnv -- see ParseCurriculum, e.g., 2,4,90,0;2,8,180,10;4,12,360,20 -- empty for flat training"`
+	CurricAuto   bool              `desc:"if true, the curriculum advances when the epoch error falls below its criteria, instead of at the epoch of each stage"`
+	PctErrCrit   float64           `desc:"with CurricAuto, the curriculum advances when EpcPctErr is at most this"`
+	DistErrCrit  float64           `desc:"with CurricAuto, the curriculum advances when EpcDistError, a fraction of the largest distance of DistPop, is at most this"`
+	Curriculum   Curriculum        `desc:"training curriculum, from Curric"`
+	Task         TaskModes         `desc:"which task both environments pose -- AnalogyTask turns on the AnalogyC, AnalogyD layers, and PathTask presents a sequence of steps on EgoInput per trial"`
 	Time         leabra.Time       `desc:"leabra timing parameters and state"`
 	ViewOn       bool              `desc:"whether to update the network view while running"`
 	TrainUpdt    leabra.TimeScales `desc:"at what time scale to update the display during training?  Anything longer than Epoch updates at Epoch in this model"`
@@ -130,63 +173,76 @@ type Sim struct {
 	LayStatNms   []string          `desc:"names of layers to collect more detailed stats on (avg act, etc)"`
 
 	// statistics: note use float64 as that is best for etable.Table
-	AlloTarg       bool    `Determines if AlloInput is a Target or not`
-	TrlErr         float64 `inactive:"+" desc:"1 if trial was error, 0 if correct -- based on SSE = 0 (subject to .5 unit-wise tolerance)"`
-	TrlSSE         float64 `inactive:"+" desc:"current trial's sum squared error"`
-	TrlAvgSSE      float64 `inactive:"+" desc:"current trial's average sum squared error"`
-	TrlCosDiff     float64 `inactive:"+" desc:"current trial's cosine difference"`
-	EpcSSE         float64 `inactive:"+" desc:"last epoch's total sum squared error"`
-	EpcAvgSSE      float64 `inactive:"+" desc:"last epoch's average sum squared error (average over trials, and over units within layer)"`
-	EpcPctErr      float64 `inactive:"+" desc:"last epoch's average TrlErr"`
-	EpcPctCor      float64 `inactive:"+" desc:"1 - last epoch's average TrlErr"`
-	EpcCosDiff     float64 `inactive:"+" desc:"last epoch's average cosine difference for output layer (a normalized error measure, maximum of 1 when the minus phase exactly matches the plus)"`
-	EpcDistError   float64
-	EpcAngError    float64
-	EpcEgoCosDiff  float64
-	EpcAlloCosDiff float64
-	EpcPerTrlMSec  float64 `inactive:"+" desc:"how long did the epoch take per trial in wall-clock milliseconds"`
-	FirstZero      int     `inactive:"+" desc:"epoch at when SSE first went to zero"`
-	NZero          int     `inactive:"+" desc:"number of epochs in a row with zero SSE"`
-	DistanceError  float64
-	AngleError     float64
-	EgoCosDiff     float64
-	AlloCosDiff    float64
+	AlloTarg          bool    `Determines if AlloInput is a Target or not`
+	TrlErr            float64 `inactive:"+" desc:"1 if trial was error, 0 if correct -- based on SSE = 0 (subject to .5 unit-wise tolerance)"`
+	TrlSSE            float64 `inactive:"+" desc:"current trial's sum squared error"`
+	TrlAvgSSE         float64 `inactive:"+" desc:"current trial's average sum squared error"`
+	TrlCosDiff        float64 `inactive:"+" desc:"current trial's cosine difference"`
+	EpcSSE            float64 `inactive:"+" desc:"last epoch's total sum squared error"`
+	EpcAvgSSE         float64 `inactive:"+" desc:"last epoch's average sum squared error (average over trials, and over units within layer)"`
+	EpcPctErr         float64 `inactive:"+" desc:"last epoch's average TrlErr"`
+	EpcPctCor         float64 `inactive:"+" desc:"1 - last epoch's average TrlErr"`
+	EpcCosDiff        float64 `inactive:"+" desc:"last epoch's average cosine difference for output layer (a normalized error measure, maximum of 1 when the minus phase exactly matches the plus)"`
+	EpcDistError      float64
+	EpcAngError       float64
+	EpcEgoCosDiff     float64
+	EpcAlloCosDiff    float64
+	EpcAnalogyError   float64 `inactive:"+" desc:"last epoch's average AnalogyError"`
+	EpcPickedDistract float64 `inactive:"+" desc:"last epoch's proportion of Distance, Angle trials where a distractor was picked"`
+	EpcMetricError    float64 `inactive:"+" desc:"last epoch's average MetricError, over the trials where no distractor was picked"`
+	EpcPerTrlMSec     float64 `inactive:"+" desc:"how long did the epoch take per trial in wall-clock milliseconds"`
+	FirstZero         int     `inactive:"+" desc:"epoch at when SSE first went to zero"`
+	NZero             int     `inactive:"+" desc:"number of epochs in a row with zero SSE"`
+	DistanceError     float64
+	AngleError        float64
+	EgoCosDiff        float64
+	AlloCosDiff       float64
+	AnalogyError      float64 `inactive:"+" desc:"in AnalogyTask, distance in grid units between the decoded AnalogyD point and the actual D"`
+	PickedDistract    float64 `inactive:"+" desc:"with distractors, 1 if the point at the decoded Distance, Angle is closer to a distractor than to the partner"`
+	MetricError       float64 `inactive:"+" desc:"with distractors, distance in grid units between the point at the decoded Distance, Angle and the partner"`
 
 	// internal state - view:"-"
-	TargAng        float32 `inactive:"+" desc:"actual angle"`
-	TargDist       float32
-	Pt1X           float32
-	Pt1Y           float32
-	Pt2X           float32
-	Pt2Y           float32
-	GuessAng       float32 `inactive:"+" desc:"guessed angle"`
-	SumErr         float64 `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
-	SumSSE         float64 `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
-	SumAvgSSE      float64 `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
-	SumCosDiff     float64 `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
-	SumDistError   float64
-	SumAngError    float64
-	SumEgoCosDiff  float64
-	SumAlloCosDiff float64
-	Win            *gi.Window                  `view:"-" desc:"main GUI window"`
-	NetView        *netview.NetView            `view:"-" desc:"the network viewer"`
-	ToolBar        *gi.ToolBar                 `view:"-" desc:"the master toolbar"`
-	TrnEpcPlot     *eplot.Plot2D               `view:"-" desc:"the training epoch plot"`
-	TstEpcPlot     *eplot.Plot2D               `view:"-" desc:"the testing epoch plot"`
-	TstTrlPlot     *eplot.Plot2D               `view:"-" desc:"the test-trial plot"`
-	TstCycPlot     *eplot.Plot2D               `view:"-" desc:"the test-cycle plot"`
-	RunPlot        *eplot.Plot2D               `view:"-" desc:"the run plot"`
-	TrnEpcFile     *os.File                    `view:"-" desc:"log file"`
-	RunFile        *os.File                    `view:"-" desc:"log file"`
-	ValsTsrs       map[string]*etensor.Float32 `view:"-" desc:"for holding layer values"`
-	SaveWts        bool                        `view:"-" desc:"for command-line run only, auto-save final weights after each run"`
-	NoGui          bool                        `view:"-" desc:"if true, runing in no GUI mode"`
-	LogSetParams   bool                        `view:"-" desc:"if true, print message for all params that are set"`
-	IsRunning      bool                        `view:"-" desc:"true if sim is running"`
-	StopNow        bool                        `view:"-" desc:"flag to stop running"`
-	NeedsNewRun    bool                        `view:"-" desc:"flag to initialize NewRun if last one finished"`
-	RndSeed        int64                       `view:"-" desc:"the current random seed"`
-	LastEpcTime    time.Time                   `view:"-" desc:"timer for last epoch"`
+	TargAng           float32 `inactive:"+" desc:"actual angle"`
+	TargDist          float32
+	Pt1X              float32 `inactive:"+" desc:"X coordinate of Point -- real-valued when Continuous"`
+	Pt1Y              float32
+	Pt2X              float32 `inactive:"+" desc:"X coordinate of Point2 -- real-valued when Continuous"`
+	Pt2Y              float32
+	GuessAng          float32 `inactive:"+" desc:"guessed angle"`
+	SumErr            float64 `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumSSE            float64 `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumAvgSSE         float64 `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumCosDiff        float64 `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumDistError      float64
+	SumAngError       float64
+	SumEgoCosDiff     float64
+	SumAlloCosDiff    float64
+	SumAnalogyError   float64                     `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumPickedDistract float64                     `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumMetricError    float64                     `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	NDistractTrls     int                         `view:"-" inactive:"+" desc:"number of Distance, Angle trials with distractors so far in epoch"`
+	Win               *gi.Window                  `view:"-" desc:"main GUI window"`
+	NetView           *netview.NetView            `view:"-" desc:"the network viewer"`
+	ToolBar           *gi.ToolBar                 `view:"-" desc:"the master toolbar"`
+	TrnEpcPlot        *eplot.Plot2D               `view:"-" desc:"the training epoch plot"`
+	TstEpcPlot        *eplot.Plot2D               `view:"-" desc:"the testing epoch plot"`
+	XformEpcPlot      *eplot.Plot2D               `view:"-" desc:"the transform test plot"`
+	TstTrlPlot        *eplot.Plot2D               `view:"-" desc:"the test-trial plot"`
+	TstCycPlot        *eplot.Plot2D               `view:"-" desc:"the test-cycle plot"`
+	RunPlot           *eplot.Plot2D               `view:"-" desc:"the run plot"`
+	TrnEpcFile        *os.File                    `view:"-" desc:"log file"`
+	RunFile           *os.File                    `view:"-" desc:"log file"`
+	TrnTrlFile        *os.File                    `view:"-" desc:"log file of the training trials of the current run"`
+	ValsTsrs          map[string]*etensor.Float32 `view:"-" desc:"for holding layer values"`
+	SaveWts           bool                        `view:"-" desc:"for command-line run only, auto-save final weights after each run"`
+	NoGui             bool                        `view:"-" desc:"if true, runing in no GUI mode"`
+	LogSetParams      bool                        `view:"-" desc:"if true, print message for all params that are set"`
+	IsRunning         bool                        `view:"-" desc:"true if sim is running"`
+	StopNow           bool                        `view:"-" desc:"flag to stop running"`
+	NeedsNewRun       bool                        `view:"-" desc:"flag to initialize NewRun if last one finished"`
+	RndSeed           int64                       `view:"-" desc:"the current random seed"`
+	RunSeed           int64                       `view:"-" desc:"seed of the global random source for the current run, derived from RndSeed and the run number -- the env seeds are drawn from it"`
+	LastEpcTime       time.Time                   `view:"-" desc:"timer for last epoch"`
 }
 
 // this registers this Sim Type and gives it properties that e.g.,
@@ -201,9 +257,12 @@ func (ss *Sim) New() {
 	ss.Size = 9
 	ss.Net = &leabra.Network{}
 	ss.TrnEpcLog = &etable.Table{}
+	ss.TrnTrlLog = &etable.Table{}
 	ss.TstEpcLog = &etable.Table{}
 	ss.TstTrlLog = &etable.Table{}
 	ss.TstCycLog = &etable.Table{}
+	ss.XformTrlLog = &etable.Table{}
+	ss.XformEpcLog = &etable.Table{}
 	ss.RunLog = &etable.Table{}
 	ss.RunStats = &etable.Table{}
 	ss.Params = ParamSets
@@ -213,6 +272,11 @@ func (ss *Sim) New() {
 	ss.TestUpdt = leabra.Cycle
 	ss.TestInterval = 500
 	ss.LayStatNms = []string{"EgoInput"}
+	ss.Sampler = "axis"
+	ss.Xforms = "rot90,rot180,rot270,rot45,reflx,refly"
+	ss.Curriculum.Defaults()
+	ss.PctErrCrit = ss.Curriculum.PctErrCrit
+	ss.DistErrCrit = ss.Curriculum.DistErrCrit
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////
@@ -223,10 +287,16 @@ func (ss *Sim) Config() {
 	//ss.ConfigPats()
 	ss.ConfigEnv()
 	ss.ConfigNet(ss.Net)
+	if err := ss.CheckEnvs(); err != nil {
+		log.Fatalln(err)
+	}
 	ss.ConfigTrnEpcLog(ss.TrnEpcLog)
+	ss.ConfigTrnTrlLog(ss.TrnTrlLog)
 	ss.ConfigTstEpcLog(ss.TstEpcLog)
 	ss.ConfigTstTrlLog(ss.TstTrlLog)
 	ss.ConfigTstCycLog(ss.TstCycLog)
+	ss.ConfigXformTrlLog(ss.XformTrlLog)
+	ss.ConfigXformEpcLog(ss.XformEpcLog)
 	ss.ConfigRunLog(ss.RunLog)
 }
 
@@ -241,23 +311,128 @@ func (ss *Sim) ConfigEnv() {
 
 	ss.TrainEnv.Nm = "TrainEnv"
 	ss.TrainEnv.Dsc = "training params and state"
+	ho, err := ParseHoldOut(ss.HoldOut)
+	if err != nil {
+		log.Println(err)
+	}
+	if ss.TrainEnv.Corrupt, err = ParseCorruption(ss.TrainCorrupt); err != nil {
+		log.Println(err)
+	}
+	if ss.TestEnv.Corrupt, err = ParseCorruption(ss.TestCorrupt); err != nil {
+		log.Println(err)
+	}
+	if ss.Curriculum, err = ParseCurriculum(ss.Curric); err != nil {
+		log.Println(err)
+	}
+	ss.Curriculum.Auto = ss.CurricAuto
+	ss.Curriculum.PctErrCrit = ss.PctErrCrit
+	ss.Curriculum.DistErrCrit = ss.DistErrCrit
+	scs, err := ParseScales(ss.Scales)
+	if err != nil {
+		log.Println(err)
+	}
+	xset, xfs, err := ParseTransforms(ss.Xforms) // all the envs have the same XformCue units
+	if err != nil {
+		log.Println(err)
+	}
+
+	ss.TrainEnv.Task = ss.Task
+	ss.TrainEnv.NDistract = ss.NDistract
+	ss.TrainEnv.Continuous = ss.Continuous
+	ss.TrainEnv.Wrap = ss.Wrap
+	ss.TrainEnv.Scales = scs
+	ss.TrainEnv.XformSet = xset
+	ss.TrainEnv.HeadingOn = ss.HeadingOn
+	ss.TrainEnv.HeadingStep = ss.HeadingStep
+	ss.TrainEnv.EgoAngle = ss.EgoAngle
 	ss.TrainEnv.Config(ss.Size, 100)
-	ss.TrainEnv.Validate()
+	ss.SetEnvParams("TrainEnv", &ss.TrainEnv.ExEnv)
+	if err := ss.TrainEnv.SetSampler(ss.Sampler); err != nil {
+		log.Println(err)
+	}
+	ss.TrainEnv.HoldOut = ho
+	ss.TrainEnv.ExclHeld = true
+	if err := ss.TrainEnv.Validate(); err != nil {
+		log.Println(err)
+	}
 	ss.TrainEnv.Run.Max = ss.MaxRuns // note: we are not setting epoch max -- do that manually
 
 	ss.TestEnv.Nm = "TestEnv"
 	ss.TestEnv.Dsc = "testing params and state"
+	ss.TestEnv.Task = ss.Task
+	ss.TestEnv.NDistract = ss.NDistract
+	ss.TestEnv.Wrap = ss.Wrap
+	ss.TestEnv.Scales = scs
+	ss.TestEnv.XformSet = xset
+	ss.TestEnv.HeadingOn = ss.HeadingOn
+	ss.TestEnv.HeadingStep = ss.HeadingStep
+	ss.TestEnv.EgoAngle = ss.EgoAngle
 	ss.TestEnv.Config(ss.Size, 50)
-	ss.TestEnv.Validate()
+	ss.SetEnvParams("TestEnv", &ss.TestEnv.ExEnv)
+	ss.TestEnv.Enum = true // test on all trials, in the same order every time
+	ss.TestEnv.HoldOut = ho
+	ss.TestEnv.ExclHeld = false // held-out trials are tested, and logged separately
+	if err := ss.TestEnv.SetSampler(ss.Sampler); err != nil {
+		log.Println(err)
+	}
+	if err := ss.TestEnv.Validate(); err != nil {
+		log.Println(err)
+	}
 
-	// note: to create a train / test split of pats, do this:
-	// all := etable.NewIdxView(ss.Pats)
-	// splits, _ := split.Permuted(all, []float64{.8, .2}, []string{"Train", "Test"})
-	// ss.TrainEnv.Table = splits.Splits[0]
-	// ss.TestEnv.Table = splits.Splits[1]
+	ss.XformEnv.Nm = "XformEnv"
+	ss.XformEnv.Dsc = "transform test params and state"
+	ss.XformEnv.NDistract = ss.NDistract
+	ss.XformEnv.Wrap = ss.Wrap
+	ss.XformEnv.XformSet = xset
+	ss.XformEnv.HeadingOn = ss.HeadingOn
+	ss.XformEnv.HeadingStep = ss.HeadingStep
+	ss.XformEnv.EgoAngle = ss.EgoAngle
+	ss.XformEnv.Config(ss.Size, 50)
+	ss.SetEnvParams("TestEnv", &ss.XformEnv) // also a testing env, with the same layers
+	ss.XformEnv.Enum = true                  // every trial under every transform
+	if err := ss.XformEnv.SetSampler(ss.Sampler); err != nil {
+		log.Println(err)
+	}
+	ss.XformEnv.Xforms = xfs
+	if err := ss.XformEnv.Validate(); err != nil {
+		log.Println(err)
+	}
+
+	ss.TrainEnv.Table = nil // generated unless there is a TrialsFile
+	ss.TestEnv.Table = nil
+	if ss.TrialsFile != "" {
+		ss.ConfigTrials()
+	}
 
 	ss.TrainEnv.Init(0)
 	ss.TestEnv.Init(0)
+	ss.XformEnv.Init(0)
+}
+
+// ConfigTrials loads the trials in TrialsFile and splits them between
+// TrainEnv and TestEnv -- by Role if the file has train and test roles,
+// and otherwise at random, 80% for training
+func (ss *Sim) ConfigTrials() {
+	dt, err := OpenTrials(ss.TrialsFile)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	ss.Trials = dt
+	trn, tst, err := SplitTrials(dt, .8)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	ss.TrainEnv.Table = trn
+	ss.TestEnv.Table = tst
+	ss.TestEnv.Sequential = true
+	if err := ss.TrainEnv.Validate(); err != nil {
+		log.Println(err)
+	}
+	if err := ss.TestEnv.Validate(); err != nil {
+		log.Println(err)
+	}
 }
 
 func (ss *Sim) ConfigNet(net *leabra.Network) {
@@ -265,10 +440,18 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	inp := net.AddLayer2D("EgoInput", ss.Size*2-1, ss.Size*2-1, emer.Target)
 	attn := net.AddLayer2D("Attn", ss.Size+1, ss.Size+1, emer.Input)
 	alloinput := net.AddLayer2D("AlloInput", ss.Size*2-1, ss.Size*2-1, emer.Input)
+	allofeat := net.AddLayer4D("AlloFeat", 1, ss.TrainEnv.NFeats, ss.Size*2-1, ss.Size*2-1, emer.Input)
+	featcue := net.AddLayer2D("FeatCue", 1, ss.TrainEnv.NFeats, emer.Input)
+	xcue := net.AddLayer2D("XformCue", 1, len(ss.TrainEnv.XformSet), emer.Input)
+	head := net.AddLayer2D("Heading", 1, ss.TrainEnv.NHeadUnits, emer.Input)
+	scue := net.AddLayer2D("ScaleCue", 1, ss.TrainEnv.NScaleUnits, emer.Input)
+	anac := net.AddLayer2D("AnalogyC", ss.Size*2-1, ss.Size*2-1, emer.Input)
+	anad := net.AddLayer2D("AnalogyD", ss.Size*2-1, ss.Size*2-1, emer.Target)
 	allohid := net.AddLayer2D("AlloHidden", 20, 20, emer.Hidden)
 	egohid := net.AddLayer2D("EgoHidden", 12, 12, emer.Hidden)
 	//x := net.AddLayer2D("X", 1, ss.Size, emer.Target)
 	//y := net.AddLayer2D("Y", 1, ss.Size, emer.Target)
+	// sized from TrainEnv, after its params sheet -- see SetEnvParams
 	dist := net.AddLayer2D("Distance", 1, ss.TrainEnv.NDistUnits, emer.Target)
 	ang := net.AddLayer2D("Angle", 1, ss.TrainEnv.NAngleUnits, emer.Target)
 
@@ -289,6 +472,13 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	attn.SetRelPos(relpos.Rel{Rel: relpos.Above, Other: "EgoHidden", YAlign: relpos.Front, XAlign: relpos.Left})
 	dist.SetRelPos(relpos.Rel{Rel: relpos.LeftOf, Other: "Attn", YAlign: relpos.Front, XAlign: relpos.Middle, Space: 1})
 	ang.SetRelPos(relpos.Rel{Rel: relpos.Behind, Other: "Distance", XAlign: relpos.Middle, Space: 4, Scale: 0.5})
+	allofeat.SetRelPos(relpos.Rel{Rel: relpos.Behind, Other: "AlloInput", XAlign: relpos.Left, Space: 2})
+	featcue.SetRelPos(relpos.Rel{Rel: relpos.Behind, Other: "XformCue", XAlign: relpos.Middle, Space: 2})
+	xcue.SetRelPos(relpos.Rel{Rel: relpos.Behind, Other: "Angle", XAlign: relpos.Middle, Space: 4})
+	head.SetRelPos(relpos.Rel{Rel: relpos.Behind, Other: "FeatCue", XAlign: relpos.Middle, Space: 2})
+	scue.SetRelPos(relpos.Rel{Rel: relpos.Behind, Other: "Heading", XAlign: relpos.Middle, Space: 2})
+	anac.SetRelPos(relpos.Rel{Rel: relpos.LeftOf, Other: "AlloInput", YAlign: relpos.Front, Space: 2})
+	anad.SetRelPos(relpos.Rel{Rel: relpos.LeftOf, Other: "AlloHidden", YAlign: relpos.Front, Space: 2})
 	// note: see emergent/prjn module for all the options on how to connect
 	// NewFull returns a new prjn.Full connectivity pattern
 	full := prjn.NewFull()
@@ -298,6 +488,16 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	net.ConnectLayers(attn, allohid, full, emer.Forward)
 	net.ConnectLayers(alloinput, allohid, full, emer.Forward)
 	net.ConnectLayers(allohid, alloinput, full, emer.Forward)
+	net.ConnectLayers(allofeat, allohid, full, emer.Forward)
+	net.ConnectLayers(featcue, allohid, full, emer.Forward)
+	net.ConnectLayers(xcue, allohid, full, emer.Forward)
+	net.ConnectLayers(xcue, egohid, full, emer.Forward)
+	net.ConnectLayers(head, allohid, full, emer.Forward)
+	net.ConnectLayers(head, egohid, full, emer.Forward)
+	net.ConnectLayers(scue, allohid, full, emer.Forward)
+	net.ConnectLayers(scue, egohid, full, emer.Forward)
+	net.ConnectLayers(anac, allohid, full, emer.Forward)
+	net.BidirConnectLayers(allohid, anad, full)
 	//net.BidirConnectLayers(egohid, x, full)
 	//net.BidirConnectLayers(egohid, y, full)
 	net.BidirConnectLayers(allohid, inp, full)
@@ -328,12 +528,50 @@ func (ss *Sim) Init() {
 	rand.Seed(ss.RndSeed)
 	ss.ConfigEnv() // re-config env just in case a different set of patterns was
 	// selected or patterns have been modified etc
+	if err := ss.CheckEnvs(); err != nil { // env sheets of a new ParamSet, or new Xforms, changed the shapes
+		ss.RebuildNet()
+		if err := ss.CheckEnvs(); err != nil {
+			log.Fatalln(err) // same as Config -- never train a network that does not match the envs
+		}
+	}
+	ss.SetTaskLays()
+	ss.ConfigEnvLogs()
 	ss.StopNow = false
 	ss.SetParams("", ss.LogSetParams) // all sheets
 	ss.NewRun()
 	ss.UpdateView(true)
 }
 
+// RebuildNet makes a new network, sized from the current envs -- for when the
+// env sheets of a new ParamSet, or a new Xforms, change the shapes of the
+// layers.  The logs with layer columns, their plots and the NetView follow
+// the new network.
+func (ss *Sim) RebuildNet() {
+	ss.Net = &leabra.Network{}
+	ss.ConfigNet(ss.Net)
+	ss.ConfigTstTrlLog(ss.TstTrlLog)
+	if ss.TstTrlPlot != nil {
+		ss.ConfigTstTrlPlot(ss.TstTrlPlot, ss.TstTrlLog)
+	}
+	if ss.NetView != nil {
+		ss.NetView.SetNet(ss.Net)
+	}
+}
+
+// ConfigEnvLogs re-configures the logs whose columns depend on the envs,
+// and their plots -- the per-scale columns of TstEpcLog follow Scales, and
+// the per-transform columns of XformEpcLog follow Xforms
+func (ss *Sim) ConfigEnvLogs() {
+	ss.ConfigTstEpcLog(ss.TstEpcLog)
+	ss.ConfigXformEpcLog(ss.XformEpcLog)
+	if ss.TstEpcPlot != nil {
+		ss.ConfigTstEpcPlot(ss.TstEpcPlot, ss.TstEpcLog)
+	}
+	if ss.XformEpcPlot != nil {
+		ss.ConfigXformEpcPlot(ss.XformEpcPlot, ss.XformEpcLog)
+	}
+}
+
 // NewRndSeed gets a new random seed based on current time -- otherwise uses
 // the same random seed for every run
 func (ss *Sim) NewRndSeed() {
@@ -429,6 +667,17 @@ func (ss *Sim) AlphaCyc(train bool) {
 	}
 }
 
+// CheckEnvs checks that the States of all the envs match the layers of the
+// network -- see envcheck.Layers
+func (ss *Sim) CheckEnvs() error {
+	for _, en := range []env.Env{&ss.TrainEnv, &ss.TestEnv, &ss.XformEnv} {
+		if err := envcheck.Layers(ss.Net, en); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ApplyInputs applies input patterns from given environment.
 // It is good practice to have this be a separate method with appropriate
 // args so that it can be used for various different contexts
@@ -437,9 +686,16 @@ func (ss *Sim) ApplyInputs(en env.Env) {
 	ss.Net.InitExt() // clear any existing inputs -- not strictly necessary if always
 	// going to the same layers, but good practice and cheap anyway
 
-	lays := []string{"EgoInput", "Attn", "AlloInput", "Distance", "Angle"}
+	lays := []string{"EgoInput", "Attn", "AlloInput", "AlloFeat", "FeatCue", "XformCue", "Heading", "ScaleCue", "AnalogyC", "AnalogyD", "Distance", "Angle"}
+	ev, isEx := en.(*ExEnv)
+	if isEx {
+		ev.Targs = ev.Targs[:0] // only the inputs are corrupted -- see ExEnv.State
+	}
 	for _, lnm := range lays {
 		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
+		if isEx && ly.Type() == emer.Target {
+			ev.Targs = append(ev.Targs, ly.Nm)
+		}
 		pats := en.State(ly.Nm)
 		if pats != nil {
 			ly.ApplyExt(pats)
@@ -461,11 +717,15 @@ func (ss *Sim) TrainTrial() {
 	if chg {
 		ss.LogTrnEpc(ss.TrnEpcLog)
 		ss.LrateSched(epc)
+		ss.CurricSched(epc)
 		if ss.ViewOn && ss.TrainUpdt > leabra.AlphaCycle {
 			ss.UpdateView(true)
 		}
 		if ss.TestInterval > 0 && epc%ss.TestInterval == 0 { // note: epc is *next* so won't trigger first time
 			ss.TestAll()
+			if ss.XformTest {
+				ss.TestXform()
+			}
 		}
 		if epc >= ss.MaxEpcs || (ss.NZeroStop > 0 && ss.NZero >= ss.NZeroStop) {
 			// done with training..
@@ -480,25 +740,83 @@ func (ss *Sim) TrainTrial() {
 		}
 	}
 
-	ss.AlloTarg = erand.BoolProb(0.5, -1)
-	if ss.AlloTarg {
-		alloinp := ss.Net.LayerByName("AlloInput").(leabra.LeabraLayer).AsLeabra()
+	if ss.Task == PathTask {
+		ss.SetPathTarg()
+		ss.PathSeq(&ss.TrainEnv.ExEnv, true) // train
+		return
+	}
+
+	ss.SetAlloTarg(ss.Task == RelationTask && erand.BoolProb(0.5, -1)) // A, B are always inputs for analogies
+	ss.ApplyInputs(&ss.TrainEnv.ExEnv)
+	ss.LogTrnTrl(ss.TrnTrlLog, &ss.TrainEnv.ExEnv)
+	ss.AlphaCyc(true)                       // train
+	ss.TrialStats(&ss.TrainEnv.ExEnv, true) // accumulate
+}
+
+// PathSeq runs the rest of the current PathTask sequence in given env,
+// which has already been stepped to its first step: one AlphaCyc per step,
+// and then the stats on the error at the end of the sequence.
+// If train is true, then it learns on every step, and stats are accumulated.
+func (ss *Sim) PathSeq(ev *ExEnv, train bool) {
+	for {
+		ss.ApplyInputs(ev)
+		if train {
+			ss.LogTrnTrl(ss.TrnTrlLog, ev)
+		}
+		ss.AlphaCyc(train)
+		if ev.Tick.Cur >= ev.Tick.Max-1 {
+			break
+		}
+		ev.Step()
+	}
+	ss.TrialStats(ev, train)
+}
+
+// SetAlloTarg sets AlloTarg and the layer types accordingly: if targ, then
+// AlloInput is the target and Distance, Angle are inputs, otherwise the reverse
+func (ss *Sim) SetAlloTarg(targ bool) {
+	ss.AlloTarg = targ
+	alloinp := ss.Net.LayerByName("AlloInput").(leabra.LeabraLayer).AsLeabra()
+	distance := ss.Net.LayerByName("Distance").(leabra.LeabraLayer).AsLeabra()
+	angle := ss.Net.LayerByName("Angle").(leabra.LeabraLayer).AsLeabra()
+	if targ {
 		alloinp.SetType(emer.Target)
-		distance := ss.Net.LayerByName("Distance").(leabra.LeabraLayer).AsLeabra()
 		distance.SetType(emer.Input)
-		angle := ss.Net.LayerByName("Angle").(leabra.LeabraLayer).AsLeabra()
 		angle.SetType(emer.Input)
 	} else {
-		alloinp := ss.Net.LayerByName("AlloInput").(leabra.LeabraLayer).AsLeabra()
 		alloinp.SetType(emer.Input)
-		distance := ss.Net.LayerByName("Distance").(leabra.LeabraLayer).AsLeabra()
 		distance.SetType(emer.Target)
-		angle := ss.Net.LayerByName("Angle").(leabra.LeabraLayer).AsLeabra()
 		angle.SetType(emer.Target)
 	}
-	ss.ApplyInputs(&ss.TrainEnv)
-	ss.AlphaCyc(true)   // train
-	ss.TrialStats(true) // accumulate
+}
+
+// SetPathTarg sets the layer types for the PathTask: the cumulative position
+// on AlloInput, and Distance, Angle from the origin are all targets
+func (ss *Sim) SetPathTarg() {
+	ss.AlloTarg = true
+	ss.Net.LayerByName("AlloInput").SetType(emer.Target)
+	ss.Net.LayerByName("Distance").SetType(emer.Target)
+	ss.Net.LayerByName("Angle").SetType(emer.Target)
+}
+
+// SetTaskLays turns the AnalogyC, AnalogyD layers on only for the AnalogyTask,
+// the AlloFeat, FeatCue layers only with distractors, the Heading layer only
+// if HeadingOn, the ScaleCue layer only with Scales, and makes EgoInput
+// an input for the PathTask, where it presents each step
+func (ss *Sim) SetTaskLays() {
+	off := ss.Task != AnalogyTask
+	ss.Net.LayerByName("AnalogyC").SetOff(off)
+	ss.Net.LayerByName("AnalogyD").SetOff(off)
+	off = ss.NDistract == 0
+	ss.Net.LayerByName("AlloFeat").SetOff(off)
+	ss.Net.LayerByName("FeatCue").SetOff(off)
+	ss.Net.LayerByName("Heading").SetOff(!ss.HeadingOn)
+	ss.Net.LayerByName("ScaleCue").SetOff(ss.Scales == "")
+	if ss.Task == PathTask {
+		ss.Net.LayerByName("EgoInput").SetType(emer.Input)
+	} else {
+		ss.Net.LayerByName("EgoInput").SetType(emer.Target)
+	}
 }
 
 // RunEnd is called at the end of a run -- save weights, record final log, etc here
@@ -515,16 +833,35 @@ func (ss *Sim) RunEnd() {
 // for the new run value
 func (ss *Sim) NewRun() {
 	run := ss.TrainEnv.Run.Cur
+	ss.NewRunSeeds(run)
+	if err := ss.Curriculum.Init(&ss.TrainEnv.ExEnv); err != nil {
+		log.Println(err)
+	}
 	ss.TrainEnv.Init(run)
 	ss.TestEnv.Init(run)
+	ss.XformEnv.Init(run)
 	ss.Time.Reset()
 	ss.Net.InitWts()
 	ss.InitStats()
 	ss.TrnEpcLog.SetNumRows(0)
+	ss.TrnTrlLog.SetNumRows(0)
+	ss.OpenTrnTrlFile(run)
 	ss.TstEpcLog.SetNumRows(0)
+	ss.XformEpcLog.SetNumRows(0)
 	ss.NeedsNewRun = false
 }
 
+// NewRunSeeds reseeds the global random source from RndSeed and the run
+// number, and then draws the TrainEnv and TestEnv seeds from it, so that
+// the run can be regenerated exactly from the seeds recorded in RunLog
+func (ss *Sim) NewRunSeeds(run int) {
+	ss.RunSeed = ss.RndSeed + int64(run)
+	rand.Seed(ss.RunSeed)
+	ss.TrainEnv.SetSeed(rand.Int63())
+	ss.TestEnv.SetSeed(rand.Int63())
+	ss.XformEnv.SetSeed(rand.Int63())
+}
+
 // InitStats initializes all the statistics, especially important for the
 // cumulative epoch stats -- called at start of new run
 func (ss *Sim) InitStats() {
@@ -534,6 +871,10 @@ func (ss *Sim) InitStats() {
 	ss.SumAvgSSE = 0
 	ss.SumCosDiff = 0
 	ss.SumDistError = 0
+	ss.SumAnalogyError = 0
+	ss.SumPickedDistract = 0
+	ss.SumMetricError = 0
+	ss.NDistractTrls = 0
 	ss.FirstZero = -1
 	ss.NZero = 0
 	// clear rest just to make Sim look initialized
@@ -546,18 +887,18 @@ func (ss *Sim) InitStats() {
 	ss.EpcCosDiff = 0
 }
 
-// TrialStats computes the trial-level statistics and adds them to the epoch accumulators if
-// accum is true.  Note that we're accumulating stats here on the Sim side so the
+// TrialStats computes the trial-level statistics for the trial of given env,
+// and adds them to the epoch accumulators if accum is true.  Note that we're accumulating stats here on the Sim side so the
 // core algorithm side remains as simple as possible, and doesn't need to worry about
 // different time-scales over which stats could be accumulated etc.
 // You can also aggregate directly from log data, as is done for testing stats
-func (ss *Sim) TrialStats(accum bool) {
+func (ss *Sim) TrialStats(ev *ExEnv, accum bool) {
 	//x := ss.Net.LayerByName("X").(leabra.LeabraLayer).AsLeabra()
 	//y := ss.Net.LayerByName("Y").(leabra.LeabraLayer).AsLeabra()
-	ss.Pt1X = float32(ss.TrainEnv.Point.X)
-	ss.Pt1Y = float32(ss.TrainEnv.Point.Y)
-	ss.Pt2X = float32(ss.TrainEnv.Point2.X)
-	ss.Pt2Y = float32(ss.TrainEnv.Point2.Y)
+	ss.Pt1X = ev.Point.X
+	ss.Pt1Y = ev.Point.Y
+	ss.Pt2X = ev.Point2.X
+	ss.Pt2Y = ev.Point2.Y
 	inp := ss.Net.LayerByName("EgoInput").(leabra.LeabraLayer).AsLeabra()
 
 	ss.TrlCosDiff = float64(inp.CosDiff.Cos)
@@ -572,8 +913,9 @@ func (ss *Sim) TrialStats(accum bool) {
 		allo_s, allo_a := alloinput.MSE(0.5)
 		ss.TrlSSE += allo_s
 		ss.TrlAvgSSE = (ss.TrlAvgSSE + allo_a) / 2
-		ss.TargDist = ss.TrainEnv.DistVal
-	} else {
+		ss.TargDist = ev.DistVal
+	}
+	if !ss.AlloTarg || ev.Task == PathTask { // path integration is also scored on Distance, Angle from origin
 		dist := ss.Net.LayerByName("Distance").(leabra.LeabraLayer).AsLeabra()
 		ang := ss.Net.LayerByName("Angle").(leabra.LeabraLayer).AsLeabra()
 
@@ -583,26 +925,35 @@ func (ss *Sim) TrialStats(accum bool) {
 		dist.UnitValsTensor(dtsr, "ActM")
 		ang.UnitValsTensor(angtsr, "ActM")
 
-		distVal := ss.TrainEnv.DistPop.Decode(dtsr.Values)
-		angVal := ss.TrainEnv.AnglePop.Decode(angtsr.Values)
+		distVal := ev.DistPop.Decode(dtsr.Values)
+		angVal := ev.AnglePop.Decode(angtsr.Values)
 
-		targDist := ss.TrainEnv.DistVal
-		targAng := ss.TrainEnv.AngVal
+		targDist := ev.DistVal
+		targAng := ev.AngVal
 
 		distError := math.Abs(float64(distVal - targDist))
-		angError1 := mat32.Abs(angVal - targAng)
-		angError2 := float32(0)
-		if targAng < 180 {
-			angError2 = mat32.Abs((360 + targAng) - angVal)
-		} else {
-			angError2 = mat32.Abs((targAng - 360) - angVal)
-		}
-
-		ss.DistanceError = float64(distError) / float64(ss.TrainEnv.MaxDist)
-		ss.AngleError = float64(mat32.Min(angError1, float32(angError2))) / 360
+		ss.DistanceError = float64(distError) / float64(ev.DistNorm())
+		ss.AngleError = float64(geom.AngleDist(angVal, targAng)) / 360
 		ss.TargAng = targAng
 		ss.GuessAng = angVal
-		ss.TargDist = ss.TrainEnv.DistVal
+
+		ss.PickedDistract = 0
+		ss.MetricError = 0
+		if ev.NDistract > 0 { // split error into picking the wrong object vs. getting the metrics wrong
+			picked, metric := ev.DistractError(distVal, angVal)
+			if picked {
+				ss.PickedDistract = 1
+			}
+			ss.MetricError = float64(metric)
+			if accum {
+				ss.SumPickedDistract += ss.PickedDistract
+				if !picked {
+					ss.SumMetricError += ss.MetricError
+				}
+				ss.NDistractTrls++
+			}
+		}
+		ss.TargDist = ev.DistVal
 
 		//ss.TrlCosDiff = float64(x.CosDiff.Cos+y.CosDiff.Cos) * 0.5
 		ss.TrlCosDiff = (ss.TrlCosDiff + float64(dist.CosDiff.Cos+ang.CosDiff.Cos)) / 3
@@ -616,6 +967,19 @@ func (ss *Sim) TrialStats(accum bool) {
 		ss.TrlAvgSSE = (ss.TrlAvgSSE + dist_a + ang_a) / 3
 	}
 
+	if ev.Task == AnalogyTask {
+		anad := ss.Net.LayerByName("AnalogyD").(leabra.LeabraLayer).AsLeabra()
+		dtsr := ss.ValsTsr(anad.Nm)
+		anad.UnitValsTensor(dtsr, "ActM")
+		anaErr, err := ev.AnalogyError(dtsr)
+		if err != nil {
+			log.Println(err)
+		}
+		ss.AnalogyError = float64(anaErr)
+		anad_s, _ := anad.MSE(0.5)
+		ss.TrlSSE += anad_s
+	}
+
 	if ss.TrlSSE > 0 {
 		ss.TrlErr = 1
 	} else {
@@ -630,6 +994,7 @@ func (ss *Sim) TrialStats(accum bool) {
 		ss.SumAngError += ss.AngleError
 		ss.SumEgoCosDiff += ss.EgoCosDiff
 		ss.SumAlloCosDiff += ss.AlloCosDiff
+		ss.SumAnalogyError += ss.AnalogyError
 	}
 }
 
@@ -702,6 +1067,19 @@ func (ss *Sim) LrateSched(epc int) {
 	}
 }
 
+// CurricSched advances the Curriculum of TrainEnv at the start of given
+// epoch, if it is due.  The first trial of the epoch has already been
+// generated, from the previous stage.
+func (ss *Sim) CurricSched(epc int) {
+	chg, err := ss.Curriculum.Update(&ss.TrainEnv.ExEnv, epc, ss.EpcPctErr, ss.EpcDistError)
+	if err != nil {
+		log.Println(err)
+	}
+	if chg {
+		fmt.Printf("curriculum stage %d at epoch: %d\n", ss.Curriculum.Stage, epc)
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////
 // Testing
 
@@ -721,9 +1099,15 @@ func (ss *Sim) TestTrial(returnOnChg bool) {
 		}
 	}
 
-	ss.ApplyInputs(&ss.TestEnv)
-	ss.AlphaCyc(false)   // !train
-	ss.TrialStats(false) // !accumulate
+	if ss.Task == PathTask {
+		ss.SetPathTarg()
+		ss.PathSeq(&ss.TestEnv.ExEnv, false) // !train
+	} else {
+		ss.SetAlloTarg(false) // always test decoding of Distance, Angle
+		ss.ApplyInputs(&ss.TestEnv.ExEnv)
+		ss.AlphaCyc(false)                      // !train
+		ss.TrialStats(&ss.TestEnv.ExEnv, false) // !accumulate
+	}
 	ss.LogTstTrl(ss.TstTrlLog)
 }
 
@@ -739,6 +1123,45 @@ func (ss *Sim) TestAll() {
 	}
 }
 
+// XformTrial runs one trial of the transform test suite using XformEnv
+func (ss *Sim) XformTrial(returnOnChg bool) {
+	ss.XformEnv.Step()
+
+	// Query counters FIRST
+	_, _, chg := ss.XformEnv.Counter(env.Epoch)
+	if chg {
+		ss.LogXformEpc(ss.XformEpcLog)
+		if returnOnChg {
+			return
+		}
+	}
+
+	ss.SetAlloTarg(false) // angle error is relative to the transformed target
+	ss.ApplyInputs(&ss.XformEnv)
+	ss.AlphaCyc(false)
+	ss.TrialStats(&ss.XformEnv, false)
+	ss.LogXformTrl(ss.XformTrlLog)
+}
+
+// TestXform runs through the full transform test suite
+func (ss *Sim) TestXform() {
+	ss.XformEnv.Init(ss.TrainEnv.Run.Cur)
+	for {
+		ss.XformTrial(true) // return on change -- don't wrap
+		_, _, chg := ss.XformEnv.Counter(env.Epoch)
+		if chg || ss.StopNow {
+			break
+		}
+	}
+}
+
+// RunTestXform runs the transform test suite, has stop running = false at end -- for gui
+func (ss *Sim) RunTestXform() {
+	ss.StopNow = false
+	ss.TestXform()
+	ss.Stopped()
+}
+
 // RunTestAll runs through the full set of testing items, has stop running = false at end -- for gui
 func (ss *Sim) RunTestAll() {
 	ss.StopNow = false
@@ -764,7 +1187,7 @@ func (ss *Sim) ParamsName() string {
 func (ss *Sim) SetParams(sheet string, setMsg bool) error {
 	if sheet == "" {
 		// this is important for catching typos and ensuring that all sheets can be used
-		ss.Params.ValidateSheets([]string{"Network", "Sim"})
+		ss.Params.ValidateSheets([]string{"Network", "Sim", "TrainEnv", "TestEnv"})
 	}
 	err := ss.SetParamsSet("Base", sheet, setMsg)
 	if ss.ParamSet != "" && ss.ParamSet != "Base" {
@@ -795,11 +1218,34 @@ func (ss *Sim) SetParamsSet(setNm string, sheet string, setMsg bool) error {
 			simp.Apply(ss, setMsg)
 		}
 	}
-	// note: if you have more complex environments with parameters, definitely add
-	// sheets for them, e.g., "TrainEnv", "TestEnv" etc
+	// note: the env sheets, "TrainEnv" and "TestEnv", are applied by SetEnvParams
+	// when the envs are configured, as the shapes of their states depend on them
 	return err
 }
 
+// SetEnvParams applies the given env sheet, "TrainEnv" or "TestEnv", of the
+// Base and then the current ParamSet to given env, and derives the shapes of
+// its states from the resulting values, e.g., for a sheet with
+// {Sel: "ExEnv", Params: params.Params{"ExEnv.NAngleUnits": "36"}},
+// ConfigNet makes an Angle layer of 36 units
+func (ss *Sim) SetEnvParams(sheet string, ev *ExEnv) {
+	setNms := []string{"Base"}
+	if ss.ParamSet != "" && ss.ParamSet != "Base" {
+		setNms = append(setNms, ss.ParamSet)
+	}
+	for _, setNm := range setNms {
+		pset, err := ss.Params.SetByNameTry(setNm)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		if envp, ok := pset.Sheets[sheet]; ok {
+			envp.Apply(ev, ss.LogSetParams)
+		}
+	}
+	ev.ConfigStates()
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////
 // 		Logging
 
@@ -842,6 +1288,97 @@ func (ss *Sim) LogFileName(lognm string) string {
 	return ss.Net.Nm + "_" + ss.RunName() + "_" + lognm + ".csv"
 }
 
+//////////////////////////////////////////////
+//  TrnTrlLog
+
+// LogTrnTrl adds the trial just generated by given training env to the
+// TrnTrlLog table, and its file, if SaveTrnTrls
+func (ss *Sim) LogTrnTrl(dt *etable.Table, ev *ExEnv) {
+	if !ss.SaveTrnTrls {
+		return
+	}
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	allo := 0.0
+	if ss.AlloTarg {
+		allo = 1
+	}
+	dt.SetCellFloat("Run", row, float64(ev.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(ev.Epoch.Cur))
+	dt.SetCellFloat("Trial", row, float64(ev.Trial.Cur))
+	dt.SetCellFloat("Tick", row, float64(ev.Tick.Cur))
+	dt.SetCellString("TrialName", row, ev.String())
+	dt.SetCellFloat("PointX", row, float64(ev.Point.X))
+	dt.SetCellFloat("PointY", row, float64(ev.Point.Y))
+	dt.SetCellFloat("Point2X", row, float64(ev.Point2.X))
+	dt.SetCellFloat("Point2Y", row, float64(ev.Point2.Y))
+	dt.SetCellFloat("Point3X", row, float64(ev.Point3.X))
+	dt.SetCellFloat("Point3Y", row, float64(ev.Point3.Y))
+	dt.SetCellFloat("DistVal", row, float64(ev.DistVal))
+	dt.SetCellFloat("AngVal", row, float64(ev.AngVal))
+	dt.SetCellFloat("HeadVal", row, float64(ev.HeadVal))
+	dt.SetCellFloat("Scale", row, float64(ev.ScaleVal))
+	dt.SetCellFloat("AlloTarg", row, allo)
+
+	if ss.TrnTrlFile != nil {
+		if row == 0 {
+			dt.WriteCSVHeaders(ss.TrnTrlFile, etable.Tab)
+		}
+		dt.WriteCSVRow(ss.TrnTrlFile, row, etable.Tab)
+	}
+}
+
+func (ss *Sim) ConfigTrnTrlLog(dt *etable.Table) {
+	dt.SetMetaData("name", "TrnTrlLog")
+	dt.SetMetaData("desc", "Record of every trial generated for training in the current run")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	dt.SetFromSchema(etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Trial", etensor.INT64, nil, nil},
+		{"Tick", etensor.INT64, nil, nil},
+		{"TrialName", etensor.STRING, nil, nil},
+		{"PointX", etensor.FLOAT64, nil, nil},
+		{"PointY", etensor.FLOAT64, nil, nil},
+		{"Point2X", etensor.FLOAT64, nil, nil},
+		{"Point2Y", etensor.FLOAT64, nil, nil},
+		{"Point3X", etensor.FLOAT64, nil, nil},
+		{"Point3Y", etensor.FLOAT64, nil, nil},
+		{"DistVal", etensor.FLOAT64, nil, nil},
+		{"AngVal", etensor.FLOAT64, nil, nil},
+		{"HeadVal", etensor.FLOAT64, nil, nil},
+		{"Scale", etensor.FLOAT64, nil, nil},
+		{"AlloTarg", etensor.FLOAT64, nil, nil},
+	}, 0)
+}
+
+// OpenTrnTrlFile closes the TrnTrlFile of the previous run, and if
+// SaveTrnTrls, creates the one for given run
+func (ss *Sim) OpenTrnTrlFile(run int) {
+	ss.CloseTrnTrlFile()
+	if !ss.SaveTrnTrls {
+		return
+	}
+	fnm := ss.LogFileName(fmt.Sprintf("trntrl_%03d", run))
+	f, err := os.Create(fnm)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	ss.TrnTrlFile = f
+}
+
+// CloseTrnTrlFile closes the TrnTrlFile, if open
+func (ss *Sim) CloseTrnTrlFile() {
+	if ss.TrnTrlFile != nil {
+		ss.TrnTrlFile.Close()
+		ss.TrnTrlFile = nil
+	}
+}
+
 //////////////////////////////////////////////
 //  TrnEpcLog
 
@@ -871,6 +1408,19 @@ func (ss *Sim) LogTrnEpc(dt *etable.Table) {
 	ss.SumEgoCosDiff = 0
 	ss.EpcAlloCosDiff = ss.SumAlloCosDiff / nt
 	ss.SumAlloCosDiff = 0
+	ss.EpcAnalogyError = ss.SumAnalogyError / nt
+	ss.SumAnalogyError = 0
+	ss.EpcPickedDistract = 0
+	ss.EpcMetricError = 0
+	if ss.NDistractTrls > 0 {
+		ss.EpcPickedDistract = ss.SumPickedDistract / float64(ss.NDistractTrls)
+		if npick := ss.NDistractTrls - int(ss.SumPickedDistract); npick > 0 {
+			ss.EpcMetricError = ss.SumMetricError / float64(npick)
+		}
+	}
+	ss.SumPickedDistract = 0
+	ss.SumMetricError = 0
+	ss.NDistractTrls = 0
 	if ss.FirstZero < 0 && ss.EpcPctErr == 0 {
 		ss.FirstZero = epc
 	}
@@ -900,6 +1450,10 @@ func (ss *Sim) LogTrnEpc(dt *etable.Table) {
 	dt.SetCellFloat("EpcAngError", row, ss.EpcAngError)
 	dt.SetCellFloat("EpcEgoCosDiff", row, ss.EpcEgoCosDiff)
 	dt.SetCellFloat("EpcAlloCosDiff", row, ss.EpcAlloCosDiff)
+	dt.SetCellFloat("EpcAnalogyError", row, ss.EpcAnalogyError)
+	dt.SetCellFloat("EpcPickedDistract", row, ss.EpcPickedDistract)
+	dt.SetCellFloat("EpcMetricError", row, ss.EpcMetricError)
+	dt.SetCellFloat("CurricStage", row, float64(ss.Curriculum.Stage))
 
 	for _, lnm := range ss.LayStatNms {
 		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
@@ -935,6 +1489,10 @@ func (ss *Sim) ConfigTrnEpcLog(dt *etable.Table) {
 		{"EpcAngError", etensor.FLOAT64, nil, nil},
 		{"EpcEgoCosDiff", etensor.FLOAT64, nil, nil},
 		{"EpcAlloCosDiff", etensor.FLOAT64, nil, nil},
+		{"EpcAnalogyError", etensor.FLOAT64, nil, nil},
+		{"EpcPickedDistract", etensor.FLOAT64, nil, nil},
+		{"EpcMetricError", etensor.FLOAT64, nil, nil},
+		{"CurricStage", etensor.INT64, nil, nil},
 	}
 	for _, lnm := range ss.LayStatNms {
 		sch = append(sch, etable.Column{lnm + " ActAvg", etensor.FLOAT64, nil, nil})
@@ -959,6 +1517,9 @@ func (ss *Sim) ConfigTrnEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 	plt.SetColParams("EpcAngError", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
 	plt.SetColParams("EpcEgoCosDiff", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
 	plt.SetColParams("EpcAlloCosDiff", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("EpcAnalogyError", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("EpcPickedDistract", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("EpcMetricError", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
 
 	for _, lnm := range ss.LayStatNms {
 		plt.SetColParams(lnm+" ActAvg", eplot.Off, eplot.FixMin, 0, eplot.FixMax, .5)
@@ -989,6 +1550,22 @@ func (ss *Sim) LogTstTrl(dt *etable.Table) {
 	dt.SetCellFloat("SSE", row, ss.TrlSSE)
 	dt.SetCellFloat("AvgSSE", row, ss.TrlAvgSSE)
 	dt.SetCellFloat("CosDiff", row, ss.TrlCosDiff)
+	dt.SetCellFloat("DistError", row, ss.DistanceError)
+	dt.SetCellFloat("AngError", row, ss.AngleError)
+	dt.SetCellFloat("AnalogyError", row, ss.AnalogyError)
+	dt.SetCellFloat("PickedDistract", row, ss.PickedDistract)
+	dt.SetCellFloat("MetricError", row, ss.MetricError)
+	held := 0.0
+	if ss.TestEnv.IsHeld {
+		held = 1
+	}
+	dt.SetCellFloat("HeldOut", row, held)
+	dt.SetCellFloat("Scale", row, float64(ss.TestEnv.ScaleVal))
+	cr := &ss.TestEnv.Corrupt
+	dt.SetCellFloat("Noise", row, float64(cr.Noise))
+	dt.SetCellFloat("Dropout", row, float64(cr.Dropout))
+	dt.SetCellFloat("Occlude", row, float64(cr.Occlude))
+	dt.SetCellFloat("Jitter", row, float64(cr.Jitter))
 
 	for _, lnm := range ss.LayStatNms {
 		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
@@ -1017,6 +1594,17 @@ func (ss *Sim) ConfigTstTrlLog(dt *etable.Table) {
 		{"SSE", etensor.FLOAT64, nil, nil},
 		{"AvgSSE", etensor.FLOAT64, nil, nil},
 		{"CosDiff", etensor.FLOAT64, nil, nil},
+		{"DistError", etensor.FLOAT64, nil, nil},
+		{"AngError", etensor.FLOAT64, nil, nil},
+		{"AnalogyError", etensor.FLOAT64, nil, nil},
+		{"PickedDistract", etensor.FLOAT64, nil, nil},
+		{"MetricError", etensor.FLOAT64, nil, nil},
+		{"HeldOut", etensor.FLOAT64, nil, nil},
+		{"Scale", etensor.FLOAT64, nil, nil},
+		{"Noise", etensor.FLOAT64, nil, nil},
+		{"Dropout", etensor.FLOAT64, nil, nil},
+		{"Occlude", etensor.FLOAT64, nil, nil},
+		{"Jitter", etensor.FLOAT64, nil, nil},
 	}
 	for _, lnm := range ss.LayStatNms {
 		ly := ss.Net.LayerByName(lnm).(leabra.LeabraLayer).AsLeabra()
@@ -1038,6 +1626,13 @@ func (ss *Sim) ConfigTstTrlPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 	plt.SetColParams("SSE", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
 	plt.SetColParams("AvgSSE", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 0)
 	plt.SetColParams("CosDiff", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("DistError", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("AngError", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("AnalogyError", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("PickedDistract", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("MetricError", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("HeldOut", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("Scale", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
 
 	for _, lnm := range ss.LayStatNms {
 		plt.SetColParams(lnm+" Act", eplot.Off, eplot.FixMin, 0, eplot.FixMax, .5)
@@ -1066,6 +1661,42 @@ func (ss *Sim) LogTstEpc(dt *etable.Table) {
 	dt.SetCellFloat("PctErr", row, agg.Mean(tix, "Err")[0])
 	dt.SetCellFloat("PctCor", row, 1-agg.Mean(tix, "Err")[0])
 	dt.SetCellFloat("CosDiff", row, agg.Mean(tix, "CosDiff")[0])
+	dt.SetCellFloat("AnalogyError", row, agg.Mean(tix, "AnalogyError")[0])
+	dt.SetCellFloat("PickedDistract", row, agg.Mean(tix, "PickedDistract")[0])
+	metix := etable.NewIdxView(trl) // metric error only where the right object was picked
+	metix.Filter(func(et *etable.Table, row int) bool {
+		return et.CellFloat("PickedDistract", row) == 0
+	})
+	dt.SetCellFloat("MetricError", row, agg.Mean(metix, "MetricError")[0])
+
+	// in-distribution vs. held-out breakdown -- the difference is the generalization gap
+	inix := etable.NewIdxView(trl)
+	inix.Filter(func(et *etable.Table, row int) bool {
+		return et.CellFloat("HeldOut", row) == 0
+	})
+	heldix := etable.NewIdxView(trl)
+	heldix.Filter(func(et *etable.Table, row int) bool {
+		return et.CellFloat("HeldOut", row) == 1
+	})
+	dt.SetCellFloat("PctErrIn", row, agg.Mean(inix, "Err")[0])
+	dt.SetCellFloat("PctErrHeld", row, agg.Mean(heldix, "Err")[0])
+	dt.SetCellFloat("DistErrIn", row, agg.Mean(inix, "DistError")[0])
+	dt.SetCellFloat("DistErrHeld", row, agg.Mean(heldix, "DistError")[0])
+	dt.SetCellFloat("AngErrIn", row, agg.Mean(inix, "AngError")[0])
+	dt.SetCellFloat("AngErrHeld", row, agg.Mean(heldix, "AngError")[0])
+
+	// distance and analogy error for each scale factor -- proportional
+	// representation of magnitude should keep them flat across scales
+	for _, sc := range ss.TestEnv.Scales {
+		sc := float64(sc)
+		six := etable.NewIdxView(trl)
+		six.Filter(func(et *etable.Table, row int) bool {
+			return et.CellFloat("Scale", row) == sc
+		})
+		nm := ScaleColName(sc)
+		dt.SetCellFloat("DistErr_"+nm, row, agg.Mean(six, "DistError")[0])
+		dt.SetCellFloat("AnalogyErr_"+nm, row, agg.Mean(six, "AnalogyError")[0])
+	}
 
 	trlix := etable.NewIdxView(trl)
 	trlix.Filter(func(et *etable.Table, row int) bool {
@@ -1089,7 +1720,7 @@ func (ss *Sim) ConfigTstEpcLog(dt *etable.Table) {
 	dt.SetMetaData("read-only", "true")
 	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
 
-	dt.SetFromSchema(etable.Schema{
+	sch := etable.Schema{
 		{"Run", etensor.INT64, nil, nil},
 		{"Epoch", etensor.INT64, nil, nil},
 		{"SSE", etensor.FLOAT64, nil, nil},
@@ -1097,7 +1728,20 @@ func (ss *Sim) ConfigTstEpcLog(dt *etable.Table) {
 		{"PctErr", etensor.FLOAT64, nil, nil},
 		{"PctCor", etensor.FLOAT64, nil, nil},
 		{"CosDiff", etensor.FLOAT64, nil, nil},
-	}, 0)
+		{"AnalogyError", etensor.FLOAT64, nil, nil},
+		{"PickedDistract", etensor.FLOAT64, nil, nil},
+		{"MetricError", etensor.FLOAT64, nil, nil},
+		{"PctErrIn", etensor.FLOAT64, nil, nil},
+		{"PctErrHeld", etensor.FLOAT64, nil, nil},
+		{"DistErrIn", etensor.FLOAT64, nil, nil},
+		{"DistErrHeld", etensor.FLOAT64, nil, nil},
+		{"AngErrIn", etensor.FLOAT64, nil, nil},
+		{"AngErrHeld", etensor.FLOAT64, nil, nil},
+	}
+	for _, nm := range ScaleColNames(ss.TestEnv.Scales) {
+		sch = append(sch, etable.Column{nm, etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, 0)
 }
 
 func (ss *Sim) ConfigTstEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
@@ -1112,6 +1756,132 @@ func (ss *Sim) ConfigTstEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 	plt.SetColParams("PctErr", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1) // default plot
 	plt.SetColParams("PctCor", eplot.On, eplot.FixMin, 0, eplot.FixMax, 1) // default plot
 	plt.SetColParams("CosDiff", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("AnalogyError", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("PickedDistract", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("MetricError", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("PctErrIn", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("PctErrHeld", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("DistErrIn", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("DistErrHeld", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("AngErrIn", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("AngErrHeld", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	for _, nm := range ScaleColNames(ss.TestEnv.Scales) {
+		plt.SetColParams(nm, eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 1)
+	}
+	return plt
+}
+
+//////////////////////////////////////////////
+//  XformTrlLog
+
+// LogXformTrl adds data from current transform test trial to the XformTrlLog table.
+func (ss *Sim) LogXformTrl(dt *etable.Table) {
+	epc := ss.TrainEnv.Epoch.Prv // this is triggered by increment so use previous value
+
+	trl := ss.XformEnv.Trial.Cur
+	row := trl
+
+	if dt.Rows <= row {
+		dt.SetNumRows(row + 1)
+	}
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	dt.SetCellFloat("Trial", row, float64(trl))
+	dt.SetCellString("TrialName", row, ss.XformEnv.String())
+	dt.SetCellString("Xform", row, ss.XformEnv.XformSet[ss.XformEnv.Xform].Name)
+	dt.SetCellFloat("Err", row, ss.TrlErr)
+	dt.SetCellFloat("SSE", row, ss.TrlSSE)
+	dt.SetCellFloat("DistError", row, ss.DistanceError)
+	dt.SetCellFloat("AngError", row, ss.AngleError)
+	dt.SetCellFloat("TargAng", row, float64(ss.TargAng))
+	dt.SetCellFloat("GuessAng", row, float64(ss.GuessAng))
+}
+
+func (ss *Sim) ConfigXformTrlLog(dt *etable.Table) {
+	dt.SetMetaData("name", "XformTrlLog")
+	dt.SetMetaData("desc", "Record of transform testing per input pattern")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	dt.SetFromSchema(etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Trial", etensor.INT64, nil, nil},
+		{"TrialName", etensor.STRING, nil, nil},
+		{"Xform", etensor.STRING, nil, nil},
+		{"Err", etensor.FLOAT64, nil, nil},
+		{"SSE", etensor.FLOAT64, nil, nil},
+		{"DistError", etensor.FLOAT64, nil, nil},
+		{"AngError", etensor.FLOAT64, nil, nil},
+		{"TargAng", etensor.FLOAT64, nil, nil},
+		{"GuessAng", etensor.FLOAT64, nil, nil},
+	}, 0)
+}
+
+//////////////////////////////////////////////
+//  XformEpcLog
+
+func (ss *Sim) LogXformEpc(dt *etable.Table) {
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+
+	trl := ss.XformTrlLog
+	tix := etable.NewIdxView(trl)
+	epc := ss.TrainEnv.Epoch.Prv
+
+	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
+	dt.SetCellFloat("Epoch", row, float64(epc))
+	dt.SetCellFloat("PctErr", row, agg.Mean(tix, "Err")[0])
+	dt.SetCellFloat("DistError", row, agg.Mean(tix, "DistError")[0])
+	dt.SetCellFloat("AngError", row, agg.Mean(tix, "AngError")[0])
+
+	// angle error relative to the transformed target, for each transform
+	for _, xf := range ss.XformEnv.Xforms {
+		nm := ss.XformEnv.XformSet[xf].Name
+		xix := etable.NewIdxView(trl)
+		xix.Filter(func(et *etable.Table, row int) bool {
+			return et.CellString("Xform", row) == nm
+		})
+		dt.SetCellFloat("AngErr_"+nm, row, agg.Mean(xix, "AngError")[0])
+	}
+
+	// note: essential to use Go version of update when called from another goroutine
+	ss.XformEpcPlot.GoUpdate()
+}
+
+func (ss *Sim) ConfigXformEpcLog(dt *etable.Table) {
+	dt.SetMetaData("name", "XformEpcLog")
+	dt.SetMetaData("desc", "Summary stats for transform testing trials")
+	dt.SetMetaData("read-only", "true")
+	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
+
+	sch := etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"PctErr", etensor.FLOAT64, nil, nil},
+		{"DistError", etensor.FLOAT64, nil, nil},
+		{"AngError", etensor.FLOAT64, nil, nil},
+	}
+	for _, xf := range ss.XformEnv.Xforms {
+		sch = append(sch, etable.Column{"AngErr_" + ss.XformEnv.XformSet[xf].Name, etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+func (ss *Sim) ConfigXformEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Example Env Transform Testing Plot"
+	plt.Params.XAxisCol = "Epoch"
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Run", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("Epoch", eplot.Off, eplot.FixMin, 0, eplot.FloatMax, 0)
+	plt.SetColParams("PctErr", eplot.Off, eplot.FixMin, 0, eplot.FixMax, 1)
+	plt.SetColParams("DistError", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	plt.SetColParams("AngError", eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	for _, xf := range ss.XformEnv.Xforms {
+		plt.SetColParams("AngErr_"+ss.XformEnv.XformSet[xf].Name, eplot.On, eplot.FixMin, 0, eplot.FloatMax, 1)
+	}
 	return plt
 }
 
@@ -1190,6 +1960,14 @@ func (ss *Sim) LogRun(dt *etable.Table) {
 
 	dt.SetCellFloat("Run", row, float64(run))
 	dt.SetCellString("Params", row, params)
+	dt.SetCellString("Task", row, ss.TrainEnv.Task.String())
+	dt.SetCellString("Sampler", row, ss.TrainEnv.Sampler)
+	dt.SetCellString("HoldOut", row, ss.TrainEnv.HoldOut.String())
+	dt.SetCellString("Curric", row, ss.Curriculum.String())
+	// note: seeds are set directly as int64 -- float64 would lose precision
+	dt.ColByName("RunSeed").(*etensor.Int64).Values[row] = ss.RunSeed
+	dt.ColByName("TrainSeed").(*etensor.Int64).Values[row] = ss.TrainEnv.Seed
+	dt.ColByName("TestSeed").(*etensor.Int64).Values[row] = ss.TestEnv.Seed
 	dt.SetCellFloat("FirstZero", row, float64(ss.FirstZero))
 	dt.SetCellFloat("SSE", row, agg.Mean(epcix, "SSE")[0])
 	dt.SetCellFloat("AvgSSE", row, agg.Mean(epcix, "AvgSSE")[0])
@@ -1198,7 +1976,7 @@ func (ss *Sim) LogRun(dt *etable.Table) {
 	dt.SetCellFloat("CosDiff", row, agg.Mean(epcix, "CosDiff")[0])
 
 	runix := etable.NewIdxView(dt)
-	spl := split.GroupBy(runix, []string{"Params"})
+	spl := split.GroupBy(runix, []string{"Params", "Task", "Sampler", "Curric"})
 	split.Desc(spl, "FirstZero")
 	split.Desc(spl, "PctCor")
 	ss.RunStats = spl.AggsToTable(etable.AddAggName)
@@ -1222,6 +2000,13 @@ func (ss *Sim) ConfigRunLog(dt *etable.Table) {
 	dt.SetFromSchema(etable.Schema{
 		{"Run", etensor.INT64, nil, nil},
 		{"Params", etensor.STRING, nil, nil},
+		{"Task", etensor.STRING, nil, nil},
+		{"Sampler", etensor.STRING, nil, nil},
+		{"HoldOut", etensor.STRING, nil, nil},
+		{"Curric", etensor.STRING, nil, nil},
+		{"RunSeed", etensor.INT64, nil, nil},
+		{"TrainSeed", etensor.INT64, nil, nil},
+		{"TestSeed", etensor.INT64, nil, nil},
 		{"FirstZero", etensor.FLOAT64, nil, nil},
 		{"SSE", etensor.FLOAT64, nil, nil},
 		{"AvgSSE", etensor.FLOAT64, nil, nil},
@@ -1303,6 +2088,9 @@ func (ss *Sim) ConfigGui() *gi.Window {
 	plt = tv.AddNewTab(eplot.KiT_Plot2D, "TstEpcPlot").(*eplot.Plot2D)
 	ss.TstEpcPlot = ss.ConfigTstEpcPlot(plt, ss.TstEpcLog)
 
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "XformEpcPlot").(*eplot.Plot2D)
+	ss.XformEpcPlot = ss.ConfigXformEpcPlot(plt, ss.XformEpcLog)
+
 	plt = tv.AddNewTab(eplot.KiT_Plot2D, "RunPlot").(*eplot.Plot2D)
 	ss.RunPlot = ss.ConfigRunPlot(plt, ss.RunLog)
 
@@ -1387,6 +2175,16 @@ func (ss *Sim) ConfigGui() *gi.Window {
 		}
 	})
 
+	tbar.AddAction(gi.ActOpts{Label: "Test Xforms", Icon: "fast-fwd", Tooltip: "Tests all of the trials under each of the Xforms transforms.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			tbar.UpdateActions()
+			go ss.RunTestXform()
+		}
+	})
+
 	tbar.AddSeparator("log")
 
 	tbar.AddAction(gi.ActOpts{Label: "Reset RunLog", Icon: "reset", Tooltip: "Reset the accumulated log of all Runs, which are tagged with the ParamSet used"}, win.This(),
@@ -1500,9 +2298,31 @@ func (ss *Sim) CmdArgs() {
 	var saveEpcLog bool
 	var saveRunLog bool
 	var note string
+	var task string
+	var headStep float64
 	flag.StringVar(&ss.ParamSet, "params", "", "ParamSet name to use -- must be valid name as listed in compiled-in params or loaded params")
 	flag.StringVar(&ss.Tag, "tag", "", "extra tag to add to file names saved from this run")
 	flag.StringVar(&note, "note", "", "user note -- describe the run params etc")
+	flag.StringVar(&task, "task", "RelationTask", "task to train and test on -- RelationTask, AnalogyTask or PathTask")
+	flag.IntVar(&ss.NDistract, "distract", 0, "number of distractor points to add to AlloInput")
+	flag.StringVar(&ss.Sampler, "sampler", "axis", "displacement sampler to train and test with -- axis, compass, lattice or angle")
+	flag.BoolVar(&ss.Continuous, "continuous", false, "if true, train on real-valued points and displacements off the integer lattice")
+	flag.BoolVar(&ss.Wrap, "wrap", false, "if true, the grid wraps around at the edges, as a torus")
+	flag.StringVar(&ss.Scales, "scales", "", "comma-separated scale factors of the displacement in scale analogies, e.g., 0.5,2,3")
+	flag.BoolVar(&ss.HeadingOn, "heading", false, "if true, the agent has a random heading, and EgoInput is in its frame")
+	flag.Float64Var(&headStep, "headstep", 0, "headings are multiples of this many degrees, e.g., 90 -- 0 for any heading")
+	flag.BoolVar(&ss.EgoAngle, "egoangle", false, "if true, the Angle target is the egocentric bearing instead of the allocentric one")
+	flag.StringVar(&ss.TrialsFile, "trials", "", "TSV or CSV file of trials to train and test on, with StartX, StartY, EndX, EndY and optional EgoX, EgoY, Role, Name columns")
+	flag.StringVar(&ss.HoldOut, "holdout", "", "trials held out of training, e.g., disp:4,0;region:0,0,3,3;sector:30,60")
+	flag.StringVar(&ss.Curric, "curric", "", "stages of the training curriculum, each MinDist,MaxDist,MaxAngle,Epoch, e.g., 2,4,90,0;2,8,180,10;4,12,360,20")
+	flag.BoolVar(&ss.CurricAuto, "curricauto", false, "if true, advance the curriculum when the epoch error falls below criterion, instead of by epoch")
+	flag.Float64Var(&ss.PctErrCrit, "curricpcterr", ss.PctErrCrit, "with curricauto, advance when the epoch PctErr is at most this")
+	flag.Float64Var(&ss.DistErrCrit, "curricdisterr", ss.DistErrCrit, "with curricauto, advance when the epoch DistError, as a fraction of the largest encoded distance, is at most this")
+	flag.StringVar(&ss.TrainCorrupt, "trncorrupt", "", "corruption of the training inputs, e.g., noise:0.1;dropout:0.2;occlude:3;jitter:0.5")
+	flag.StringVar(&ss.TestCorrupt, "tstcorrupt", "", "corruption of the testing inputs, e.g., noise:0.1;dropout:0.2;occlude:3;jitter:0.5")
+	flag.StringVar(&ss.Xforms, "xforms", ss.Xforms, "comma-separated transforms tested by the transform test suite, e.g., rot90,rot180,rot270,rot45,reflx,refly -- rot:<deg> rotates by any angle")
+	flag.BoolVar(&ss.SaveTrnTrls, "trntrls", false, "if true, save every generated training trial to a separate file for each run")
+	flag.BoolVar(&ss.XformTest, "xformtest", false, "if true, run the transform test suite along with the regular test")
 	flag.IntVar(&ss.MaxRuns, "runs", 10, "number of runs to do (note that MaxEpcs is in paramset)")
 	flag.BoolVar(&ss.LogSetParams, "setparams", false, "if true, print a record of each parameter that is set")
 	flag.BoolVar(&ss.SaveWts, "wts", false, "if true, save final weights after each run")
@@ -1510,7 +2330,11 @@ func (ss *Sim) CmdArgs() {
 	flag.BoolVar(&saveRunLog, "runlog", true, "if true, save run epoch log to file")
 	flag.BoolVar(&nogui, "nogui", true, "if not passing any other args and want to run nogui, use nogui")
 	flag.Parse()
-	ss.Init()
+	if err := ss.Task.FromString(task); err != nil {
+		log.Println(err)
+	}
+	ss.HeadingStep = float32(headStep)
+	ss.Init() // rebuilds the network if the env sheets of the ParamSet change the layer sizes
 
 	if note != "" {
 		fmt.Printf("note: %s\n", note)
@@ -1518,6 +2342,20 @@ func (ss *Sim) CmdArgs() {
 	if ss.ParamSet != "" {
 		fmt.Printf("Using ParamSet: %s\n", ss.ParamSet)
 	}
+	fmt.Printf("Using Task: %s\n", ss.Task)
+	fmt.Printf("Using Sampler: %s\n", ss.Sampler)
+	if ss.Continuous {
+		fmt.Printf("Using continuous coordinates\n")
+	}
+	if ss.Wrap {
+		fmt.Printf("Using wrap-around grid\n")
+	}
+	if ss.Scales != "" {
+		fmt.Printf("Using scale analogies, scales: %s\n", ss.Scales)
+	}
+	if ss.HeadingOn {
+		fmt.Printf("Using agent heading, step: %g, ego angle: %v\n", ss.HeadingStep, ss.EgoAngle)
+	}
 
 	if saveEpcLog {
 		var err error
@@ -1546,6 +2384,10 @@ func (ss *Sim) CmdArgs() {
 	if ss.SaveWts {
 		fmt.Printf("Saving final weights per run\n")
 	}
+	if ss.SaveTrnTrls {
+		fmt.Printf("Saving generated training trials per run to: %v\n", ss.LogFileName("trntrl_*"))
+		defer ss.CloseTrnTrlFile()
+	}
 	fmt.Printf("Running %d Runs\n", ss.MaxRuns)
 	ss.Train()
 }
@@ -0,0 +1,209 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/split"
+	"github.com/goki/gi/gi"
+	"github.com/goki/mat32"
+)
+
+// TrialCols are the columns that every trials table must have: the start
+// point and the end point of each trial.  Optional columns are EgoX, EgoY,
+// the ego vector presented on EgoInput if it is not just End - Start,
+// Role, e.g., train or test, and Name, used as the trial name in logs.
+// An ego vector from EgoX, EgoY is presented as is, without any rotation
+// by the heading.
+var TrialCols = []string{"StartX", "StartY", "EndX", "EndY"}
+
+// OpenTrials opens a table of trials from given file, which is read as
+// comma-separated if it has a .csv extension, and tab-separated otherwise.
+// The first row has the column names -- see TrialCols.
+func OpenTrials(fname string) (*etable.Table, error) {
+	delim := etable.Tab
+	if strings.ToLower(filepath.Ext(fname)) == ".csv" {
+		delim = etable.Comma
+	}
+	dt := &etable.Table{}
+	if err := dt.OpenCSV(gi.FileName(fname), delim); err != nil {
+		return nil, err
+	}
+	if err := CheckTrials(dt); err != nil {
+		return nil, fmt.Errorf("OpenTrials: %v: %v", fname, err)
+	}
+	return dt, nil
+}
+
+// CheckTrials returns an error if given table is missing any of the TrialCols
+func CheckTrials(dt *etable.Table) error {
+	for _, cn := range TrialCols {
+		if dt.ColByName(cn) == nil {
+			return fmt.Errorf("trials table has no %v column -- needs: %v", cn, strings.Join(TrialCols, ", "))
+		}
+	}
+	return nil
+}
+
+// SplitTrials splits the trials into train and test views: by the Role
+// column if any row has the test role, and otherwise at random, with
+// given fraction of the rows for training.  If there is a Role column,
+// only its rows with the train role are split at random, and it is an
+// error if there are none.  Rows with any other role are in neither view.
+func SplitTrials(dt *etable.Table, trainFrac float64) (trn, tst *etable.IdxView, err error) {
+	all := etable.NewIdxView(dt)
+	if dt.ColByName("Role") != nil {
+		trn = RoleTrials(dt, "train")
+		tst = RoleTrials(dt, "test")
+		if tst.Len() > 0 {
+			return
+		}
+		if trn.Len() == 0 {
+			return nil, nil, fmt.Errorf("SplitTrials: no row has the train or test Role")
+		}
+		all = trn
+	}
+	splits, err := split.Permuted(all, []float64{trainFrac, 1 - trainFrac}, []string{"Train", "Test"})
+	if err != nil {
+		return nil, nil, err
+	}
+	return splits.Splits[0], splits.Splits[1], nil
+}
+
+// RoleTrials returns a view of the rows of given table with given Role,
+// ignoring case
+func RoleTrials(dt *etable.Table, role string) *etable.IdxView {
+	ix := etable.NewIdxView(dt)
+	ix.Filter(func(et *etable.Table, row int) bool {
+		return strings.EqualFold(strings.TrimSpace(et.CellString("Role", row)), role)
+	})
+	return ix
+}
+
+// TableEnv is an ExEnv that presents the trials in the rows of a table,
+// e.g., a curated or hand-designed stimulus set loaded with OpenTrials,
+// instead of generating them.  Rows are presented in Sequential or
+// permuted order as in env.FixedTable, and all the states are encoded by
+// the ExEnv.  If Table is nil, trials are generated by the ExEnv as usual.
+type TableEnv struct {
+	ExEnv
+	Table      *etable.IdxView `desc:"indexed view of the trials table to present, e.g., the rows for one Role -- nil to generate trials instead"`
+	Sequential bool            `desc:"present rows in the order of the Table view, otherwise in a random order that is permuted every epoch"`
+	Order      []int           `desc:"permuted order of rows to present if not Sequential"`
+	TrialName  string          `inactive:"+" desc:"if Table has a Name column, the name of the current trial"`
+}
+
+// Validate checks the ExEnv, and that the Table has the TrialCols and
+// every row fits the grid
+func (ev *TableEnv) Validate() error {
+	if err := ev.ExEnv.Validate(); err != nil || ev.Table == nil {
+		return err
+	}
+	if ev.Task == PathTask {
+		return fmt.Errorf("TableEnv: %v cannot present PathTask sequences from a table", ev.Nm)
+	}
+	if err := CheckTrials(ev.Table.Table); err != nil {
+		return fmt.Errorf("TableEnv: %v: %v", ev.Nm, err)
+	}
+	if ev.Table.Len() == 0 {
+		return fmt.Errorf("TableEnv: %v Table has no rows", ev.Nm)
+	}
+	mx := float32(ev.Size - 1)
+	for i := 0; i < ev.Table.Len(); i++ {
+		start, end, ego := ev.RowPoints(ev.Table.Idxs[i])
+		for _, pt := range []mat32.Vec2{start, end} {
+			if pt.X < 0 || pt.Y < 0 || pt.X > mx || pt.Y > mx {
+				return fmt.Errorf("TableEnv: %v row %d: point %v is off the %d x %d grid", ev.Nm, ev.Table.Idxs[i], pt, ev.Size, ev.Size)
+			}
+		}
+		if mat32.Abs(ego.X) > mx || mat32.Abs(ego.Y) > mx {
+			return fmt.Errorf("TableEnv: %v row %d: ego vector %v does not fit in EgoInput shape %v", ev.Nm, ev.Table.Idxs[i], ego, ev.EgoInput.Shp)
+		}
+	}
+	return nil
+}
+
+// Init initializes the counters, with one Trial per row of the Table
+func (ev *TableEnv) Init(run int) {
+	ev.ExEnv.Init(run)
+	if ev.Table == nil {
+		return
+	}
+	np := ev.Table.Len()
+	ev.Order = ev.Rand.Perm(np)
+	ev.Trial.Max = np
+}
+
+// Row returns the current row number in the Table, through the Order if not Sequential
+func (ev *TableEnv) Row() int {
+	if ev.Sequential {
+		return ev.Table.Idxs[ev.Trial.Cur]
+	}
+	return ev.Table.Idxs[ev.Order[ev.Trial.Cur]]
+}
+
+// RowPoints returns the start point, end point and ego vector of given row
+// of the Table -- the ego vector is End - Start (the shortest toroidal vector
+// if Wrap) unless the table has EgoX, EgoY columns
+func (ev *TableEnv) RowPoints(row int) (start, end, ego mat32.Vec2) {
+	dt := ev.Table.Table
+	start = mat32.NewVec2(float32(dt.CellFloat("StartX", row)), float32(dt.CellFloat("StartY", row)))
+	end = mat32.NewVec2(float32(dt.CellFloat("EndX", row)), float32(dt.CellFloat("EndY", row)))
+	ego = ev.WrapVec(end.Sub(start))
+	if dt.ColByName("EgoX") != nil && dt.ColByName("EgoY") != nil {
+		ego = mat32.NewVec2(float32(dt.CellFloat("EgoX", row)), float32(dt.CellFloat("EgoY", row)))
+	}
+	return
+}
+
+// SetRow sets the states from given row of the Table
+func (ev *TableEnv) SetRow(row int) {
+	start, end, ego := ev.RowPoints(row)
+	ev.Xform = 0
+	ev.ScaleVal = ev.SampleScale()
+	ev.SampleHeading()
+	ev.SetPoints(start, ev.WrapVec(end.Sub(start)))
+	ev.TrialName = ""
+	if ev.Table.Table.ColByName("Name") != nil {
+		ev.TrialName = ev.Table.Table.CellString("Name", row)
+	}
+	if ev.Table.Table.ColByName("EgoX") == nil || ev.Table.Table.ColByName("EgoY") == nil {
+		return
+	}
+	c := float32(ev.Center())
+	ev.Point3 = mat32.NewVec2(c+ego.X, c+ego.Y)
+	ev.EgoInput.SetZeros()
+	ev.EgoInputPop.Encode(&ev.EgoInput, ev.PopPos(ev.Point3), false)
+}
+
+// String returns the Name of the current row if the table has one
+func (ev *TableEnv) String() string {
+	if ev.Table != nil && ev.TrialName != "" {
+		return ev.TrialName
+	}
+	return ev.ExEnv.String()
+}
+
+// Step presents the next row of the Table, permuting the Order at the end of each epoch
+func (ev *TableEnv) Step() bool {
+	if ev.Table == nil {
+		return ev.ExEnv.Step()
+	}
+	ev.Epoch.Same() // good idea to just reset all non-inner-most counters at start
+	if ev.Trial.Incr() {
+		ev.Rand.Shuffle(len(ev.Order), func(i, j int) { ev.Order[i], ev.Order[j] = ev.Order[j], ev.Order[i] })
+		ev.Epoch.Incr()
+	}
+	ev.SetRow(ev.Row())
+	return true
+}
+
+// Compile-time check that implements Env interface
+var _ env.Env = (*TableEnv)(nil)
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emer/etable/etable"
+)
+
+const testTrials = `Name	StartX	StartY	EndX	EndY	EgoX	EgoY	Role
+a	0	0	3	0	3	0	train
+b	2	2	2	6	0	4	train
+c	4	1	1	5	-3	4	train
+d	8	8	5	8	-2	0	test
+e	1	7	6	7	5	0	other
+`
+
+func TestTableEnv(t *testing.T) {
+	dt := &etable.Table{}
+	if err := dt.ReadCSV(strings.NewReader(testTrials), etable.Tab); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckTrials(dt); err != nil {
+		t.Fatal(err)
+	}
+	trn, tst, err := SplitTrials(dt, .8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trn.Len() != 3 || tst.Len() != 1 {
+		t.Fatalf("split by role into %d train, %d test rows", trn.Len(), tst.Len())
+	}
+
+	ev := TableEnv{}
+	ev.Config(9, 10)
+	ev.SetSampler("lattice")
+	ev.Table = trn
+	ev.Sequential = true
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ev.Init(0)
+	for i := 0; i < trn.Len(); i++ {
+		ev.Step()
+		start, end, ego := ev.RowPoints(trn.Idxs[i])
+		if ev.Point != start || ev.Point2 != end || ev.TrialName != dt.CellString("Name", trn.Idxs[i]) {
+			t.Errorf("trial %d: %v %v %v is not row %d", i, ev.Point, ev.Point2, ev.TrialName, trn.Idxs[i])
+		}
+		if ev.Point3 != ego.AddScalar(float32(ev.Center())) {
+			t.Errorf("trial %d: Point3 %v is not the centered ego vector %v", i, ev.Point3, ego)
+		}
+	}
+
+	ev.Sequential = false // permuted: every row once per epoch
+	ev.Init(0)
+	for epc := 0; epc < 3; epc++ {
+		seen := map[string]bool{}
+		for i := 0; i < trn.Len(); i++ {
+			ev.Step()
+			seen[ev.TrialName] = true
+		}
+		if len(seen) != trn.Len() {
+			t.Errorf("epoch %d: presented %v", epc, seen)
+		}
+	}
+
+	dt.SetCellFloat("EndX", 0, 12) // off the grid
+	if err := ev.Validate(); err == nil {
+		t.Errorf("expected error for point off the grid")
+	}
+}
+
+func TestSplitTrialsNoTest(t *testing.T) {
+	dt := &etable.Table{}
+	if err := dt.ReadCSV(strings.NewReader(strings.Replace(testTrials, "test\n", "other\n", 1)), etable.Tab); err != nil {
+		t.Fatal(err)
+	}
+	trn, tst, err := SplitTrials(dt, .5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trn.Len()+tst.Len() != 3 {
+		t.Errorf("split %d train, %d test rows, want the 3 train rows", trn.Len(), tst.Len())
+	}
+	for _, ix := range []*etable.IdxView{trn, tst} {
+		for _, row := range ix.Idxs {
+			if role := dt.CellString("Role", row); role != "train" {
+				t.Errorf("row %d with role %v in a split", row, role)
+			}
+		}
+	}
+
+	dt = &etable.Table{}
+	others := strings.NewReplacer("train\n", "other\n", "test\n", "other\n").Replace(testTrials)
+	if err := dt.ReadCSV(strings.NewReader(others), etable.Tab); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := SplitTrials(dt, .5); err == nil {
+		t.Errorf("expected error for a Role column with no train or test rows")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2020, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/goki/mat32"
+	"myEnv/geom"
+)
+
+// Transform is a reflection and / or rotation of the displacement between
+// Point and Point2, used to test whether a learned relation transfers to
+// its rotated or mirror-image versions.  The input states encode the
+// untransformed displacement, and the targets the transformed one, with the
+// XformCue telling which transform relates them -- see ExEnv.State.
+type Transform struct {
+	Name      string  `desc:"name used to select this transform, e.g., on the command line"`
+	Angle     float32 `desc:"counter-clockwise rotation in degrees, applied after any reflection -- multiples of 90 map lattice points exactly, other angles are rounded to the nearest lattice point"`
+	Reflect   bool    `desc:"if true, reflect across the axis at ReflAngle"`
+	ReflAngle float32 `desc:"bearing in degrees of the axis to reflect across -- 0 is the X axis and 90 is the Y axis"`
+}
+
+// Apply returns the transformed displacement, rounded to the lattice
+func (tf *Transform) Apply(d image.Point) image.Point {
+	return geom.LatticePoint(tf.ApplyVec(geom.LatticeVec(d)))
+}
+
+// ApplyVec returns the transformed continuous displacement, without rounding
+func (tf *Transform) ApplyVec(d mat32.Vec2) mat32.Vec2 {
+	if tf.Reflect {
+		d = geom.ReflectVec(d, tf.ReflAngle)
+	}
+	if tf.Angle != 0 {
+		d = geom.RotateVec(d, tf.Angle)
+	}
+	return d
+}
+
+// Transforms are the built-in transforms that can be selected by name --
+// the first is the identity, used when no transform applies
+var Transforms = []Transform{
+	{Name: "none"},
+	{Name: "rot90", Angle: 90},
+	{Name: "rot180", Angle: 180},
+	{Name: "rot270", Angle: 270},
+	{Name: "rot45", Angle: 45},
+	{Name: "reflx", Reflect: true, ReflAngle: 0},
+	{Name: "refly", Reflect: true, ReflAngle: 90},
+}
+
+// TransformByName returns the built-in transform with given name, or the
+// rotation by any angle named rot:<deg>, e.g., rot:30
+func TransformByName(nm string) (Transform, error) {
+	if strings.HasPrefix(nm, "rot:") {
+		var ang float32
+		if _, err := fmt.Sscanf(nm[len("rot:"):], "%g", &ang); err != nil {
+			return Transform{}, fmt.Errorf("TransformByName: rotation: %v is not of the form rot:<deg>: %v", nm, err)
+		}
+		return Transform{Name: fmt.Sprintf("rot:%g", ang), Angle: ang}, nil
+	}
+	nms := make([]string, len(Transforms))
+	for i := range Transforms {
+		if Transforms[i].Name == nm {
+			return Transforms[i], nil
+		}
+		nms[i] = Transforms[i].Name
+	}
+	return Transform{}, fmt.Errorf("TransformByName: transform named: %v not found -- must be one of: %v, or rot:<deg>", nm, strings.Join(nms, ", "))
+}
+
+// ParseTransforms returns the transform set for the comma-separated
+// transform names in given spec, e.g., "rot90,reflx,rot:30", in the order of
+// their units in the XformCue state: the identity first, used when no
+// transform applies, and then each named transform once.  It also returns
+// the index in the set of each name in spec, e.g., for ExEnv.Xforms.
+func ParseTransforms(spec string) (set []Transform, xfs []int, err error) {
+	set = []Transform{Transforms[0]}
+	for _, nm := range strings.Split(spec, ",") {
+		nm = strings.TrimSpace(nm)
+		if nm == "" {
+			continue
+		}
+		tf, err := TransformByName(nm)
+		if err != nil {
+			return nil, nil, err
+		}
+		xi := XformIdx(set, tf.Name)
+		if xi < 0 {
+			set = append(set, tf)
+			xi = len(set) - 1
+		}
+		xfs = append(xfs, xi)
+	}
+	return set, xfs, nil
+}
+
+// XformIdx returns the index in given transform set of the transform with
+// given name -- -1 if it is not there
+func XformIdx(set []Transform, nm string) int {
+	for i := range set {
+		if set[i].Name == nm {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"image"
+	"testing"
+
+	"github.com/emer/etable/etensor"
+	"myEnv/geom"
+)
+
+func TestTransforms(t *testing.T) {
+	cases := []struct {
+		nm   string
+		d    image.Point
+		want image.Point
+	}{
+		{"none", image.Point{3, 1}, image.Point{3, 1}},
+		{"rot90", image.Point{3, 1}, image.Point{-1, 3}},
+		{"rot180", image.Point{3, 1}, image.Point{-3, -1}},
+		{"rot270", image.Point{3, 1}, image.Point{1, -3}},
+		{"rot45", image.Point{4, 0}, image.Point{3, 3}},
+		{"reflx", image.Point{3, 1}, image.Point{3, -1}},
+		{"refly", image.Point{3, 1}, image.Point{-3, 1}},
+	}
+	for _, c := range cases {
+		tf, err := TransformByName(c.nm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tf.Apply(c.d); got != c.want {
+			t.Errorf("%v of %v: got %v, want %v", c.nm, c.d, got, c.want)
+		}
+	}
+	if _, _, err := ParseTransforms("rot90,flip"); err == nil {
+		t.Errorf("ParseTransforms did not report unknown transform")
+	}
+	n := len(Transforms)
+	set, xfs, err := ParseTransforms("rot:30,rot90,rot:30.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set) != 3 || set[0].Name != "none" || set[1].Name != "rot:30" || len(xfs) != 3 || xfs[0] != 1 || xfs[1] != 2 || xfs[2] != 1 {
+		t.Errorf("rot:30 not added once to the set: %v, %v", set, xfs)
+	}
+	if len(Transforms) != n {
+		t.Errorf("ParseTransforms changed the built-in Transforms: %v", Transforms[n:])
+	}
+	if got := set[1].Apply(image.Point{6, 0}); got != (image.Point{5, 3}) {
+		t.Errorf("rot:30 of {6, 0}: got %v", got)
+	}
+	if _, _, err := ParseTransforms("rot:x"); err == nil {
+		t.Errorf("ParseTransforms did not report bad rotation angle")
+	}
+}
+
+func TestXformTrials(t *testing.T) {
+	ev := ExEnv{}
+	ev.XformSet, ev.Xforms, _ = ParseTransforms("rot90,reflx")
+	ev.Config(9, 10)
+	ev.SetSampler("compass")
+	ev.Enum = true
+	if err := ev.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ev.Init(0)
+	n := map[int]int{}
+	for i := 0; i < ev.Trial.Max; i++ {
+		ev.Step()
+		n[ev.Xform]++
+		if ev.XformCue.Values[ev.Xform] != 1 {
+			t.Errorf("trial %d: transform %d not cued", i, ev.Xform)
+		}
+		disp := ev.Point2.Sub(ev.Point)
+		if !ev.DispOk(geom.LatticePoint(disp)) {
+			t.Errorf("trial %d: transformed displacement %v out of range", i, disp)
+		}
+		if want := ev.XformVec(ev.Xform, ev.InDisp); disp != want {
+			t.Errorf("trial %d: displacement %v is not %v of %v", i, disp, ev.XformSet[ev.Xform].Name, ev.InDisp)
+		}
+		// the inputs encode the untransformed displacement, and the targets the transformed one
+		for _, targ := range []bool{false, true} {
+			d := ev.InDisp
+			if targ {
+				d = disp
+				ev.Targs = []string{"Angle"}
+			}
+			_, ang := ev.DistAng(d)
+			var want []float32
+			ev.AnglePop.Encode(&want, ang, ev.NAngleUnits)
+			got := ev.State("Angle").(*etensor.Float32).Values
+			for u := range want {
+				if got[u] != want[u] {
+					t.Errorf("trial %d: target: %v, Angle does not encode %g, of displacement %v", i, targ, ang, d)
+					break
+				}
+			}
+		}
+		ev.Targs = nil
+	}
+	if len(n) != 2 || n[0] != 0 {
+		t.Errorf("trials per transform: %v", n)
+	}
+}